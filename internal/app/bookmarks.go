@@ -0,0 +1,246 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/bookmarks"
+	"github.com/icichainz/sushi/internal/utils"
+)
+
+// bookmarksPath is where the bookmarks are persisted
+func bookmarksPath() string {
+	return filepath.Join(utils.DataDir(), "bookmarks")
+}
+
+// bookmarkManager is the list of bookmarks, active in ModeBookmarks
+type bookmarkManager struct {
+	cursor     int
+	missing    []bool // Whether each bookmark points to a missing directory
+	renaming   bool
+	input      textinput.Model
+	returnMode Mode // Mode to go back to once closed
+}
+
+// bookmarkHints lists the keys of the bookmark manager
+const bookmarkHints = "enter jump · a add · r rename · d delete · J/K move · esc back"
+
+// setMark binds key to the current directory
+func (m Model) setMark(key string) (Model, tea.Cmd) {
+	if err := m.bookmarks.Set(key, m.currentPath); err != nil {
+		return m.fail(err), nil
+	}
+	m.statusMsg = fmt.Sprintf("Marked %s as '%s", utils.ShortenPath(m.currentPath), key)
+	return m, nil
+}
+
+// jumpToMark goes to the directory bound to key
+func (m Model) jumpToMark(key string) (Model, tea.Cmd) {
+	bm, ok := m.bookmarks.Get(key)
+	if !ok {
+		return m.fail(fmt.Errorf("no bookmark '%s", key)), nil
+	}
+	return m.jumpToBookmark(bm)
+}
+
+// addBookmark saves the current directory as a bookmark without a key
+func (m Model) addBookmark(name string) (Model, tea.Cmd) {
+	if err := m.bookmarks.Add(name, m.currentPath); err != nil {
+		return m.fail(err), nil
+	}
+	m.statusMsg = "Bookmarked " + utils.ShortenPath(m.currentPath)
+	return m.refreshBookmarks(), nil
+}
+
+// jumpToBookmark goes to a bookmarked directory, unless it is gone
+func (m Model) jumpToBookmark(bm bookmarks.Bookmark) (Model, tea.Cmd) {
+	if bm.Missing() {
+		return m.fail(fmt.Errorf("bookmark %q points to a missing directory: %s", bm.Name, bm.Path)), nil
+	}
+	return m.enterDir(bm.Path, "")
+}
+
+// openBookmarks shows the bookmark manager
+func (m Model) openBookmarks() (Model, tea.Cmd) {
+	input := textinput.New()
+	input.Prompt = "Name: "
+	m.marks = bookmarkManager{input: input, returnMode: m.mode}
+	m.mode = ModeBookmarks
+	m.statusMsg = bookmarkHints
+	return m.refreshBookmarks(), nil
+}
+
+// refreshBookmarks checks which bookmarks are missing and keeps the cursor
+// on the list
+func (m Model) refreshBookmarks() Model {
+	list := m.bookmarks.List
+	m.marks.missing = make([]bool, len(list))
+	for i, bm := range list {
+		m.marks.missing[i] = bm.Missing()
+	}
+	m.marks.cursor = max(0, min(m.marks.cursor, len(list)-1))
+	return m
+}
+
+// closeBookmarks leaves the bookmark manager
+func (m Model) closeBookmarks() Model {
+	m.mode = m.marks.returnMode
+	m.marks = bookmarkManager{}
+	m.statusMsg = ""
+	return m
+}
+
+// handleBookmarksKey processes keyboard input in the bookmark manager
+func (m Model) handleBookmarksKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.marks.renaming {
+		return m.handleBookmarkRenameKey(msg)
+	}
+
+	list := m.bookmarks.List
+	cursor := m.marks.cursor
+	var err error
+
+	m, command := m.resolveKey(msg)
+	name, args := commandName(command)
+	switch name {
+	case "cancel", "quit", "parent", "back":
+		return m.closeBookmarks(), nil
+
+	case "up", "down", "top", "bottom":
+		m.marks.cursor = moveListCursor(cursor, len(list), name, args)
+		return m, nil
+
+	case "open", "pick":
+		if len(list) == 0 {
+			return m, nil
+		}
+		bm := list[cursor]
+		return m.closeBookmarks().jumpToBookmark(bm)
+	}
+	if command != "" || len(m.pending) > 0 {
+		return m, nil
+	}
+
+	// Keys of the bookmark manager itself, unless bound to one of the above
+	switch msg.String() {
+	case "a":
+		return m.addBookmark("")
+
+	case "r":
+		if len(list) == 0 {
+			return m, nil
+		}
+		m.marks.renaming = true
+		m.marks.input.SetValue(list[cursor].Name)
+		m.marks.input.CursorEnd()
+		return m, m.marks.input.Focus()
+
+	case "d":
+		if len(list) == 0 {
+			return m, nil
+		}
+		name := list[cursor].Name
+		if err = m.bookmarks.Delete(cursor); err == nil {
+			m.statusMsg = "Deleted bookmark " + name
+		}
+
+	case "J", "shift+down":
+		if cursor+1 < len(list) {
+			if err = m.bookmarks.Move(cursor, cursor+1); err == nil {
+				m.marks.cursor++
+			}
+		}
+
+	case "K", "shift+up":
+		if cursor > 0 {
+			if err = m.bookmarks.Move(cursor, cursor-1); err == nil {
+				m.marks.cursor--
+			}
+		}
+	}
+
+	if err != nil {
+		m = m.fail(err)
+	}
+	return m.refreshBookmarks(), nil
+}
+
+// handleBookmarkRenameKey edits the name of the bookmark under the cursor
+func (m Model) handleBookmarkRenameKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyEsc:
+		m.marks.renaming = false
+		m.marks.input.Blur()
+		return m, nil
+
+	case msg.Type == tea.KeyEnter:
+		m.marks.renaming = false
+		m.marks.input.Blur()
+		name := strings.TrimSpace(m.marks.input.Value())
+		if name == "" {
+			return m, nil
+		}
+		if err := m.bookmarks.Rename(m.marks.cursor, name); err != nil {
+			return m.fail(err), nil
+		}
+		m.statusMsg = bookmarkHints
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.marks.input, cmd = m.marks.input.Update(msg)
+	return m, cmd
+}
+
+// renderBookmarks renders the bookmark list, flagging missing directories
+func (m Model) renderBookmarks() string {
+	height := m.height - 4
+	lines := []string{m.styles.Header.Render(" Bookmarks"), ""}
+
+	list := m.bookmarks.List
+	if len(list) == 0 {
+		lines = append(lines, m.styles.EmptyDir.Render("  No bookmarks yet: press a to add this directory, or m<letter> outside"))
+	}
+
+	nameWidth := 0
+	for _, bm := range list {
+		nameWidth = max(nameWidth, len([]rune(bm.Name)))
+	}
+
+	rows := max(1, height-len(lines)-1)
+	start := max(0, m.marks.cursor-rows+1)
+	end := min(len(list), start+rows)
+	for i := start; i < end; i++ {
+		bm := list[i]
+		keyLabel := " "
+		if bm.Key != "" {
+			keyLabel = bm.Key
+		}
+		line := fmt.Sprintf(" %s  %-*s  %s", keyLabel, nameWidth, bm.Name, utils.ShortenPath(bm.Path))
+		if m.marks.missing[i] {
+			line += "  (missing)"
+		}
+		line = fmt.Sprintf("%-*s", max(0, m.width-4), line)
+
+		style := m.styles.File
+		if m.marks.missing[i] {
+			style = m.styles.EmptyDir
+		}
+		if i == m.marks.cursor {
+			style = m.styles.SelectedFile
+		}
+		lines = append(lines, style.Render(line))
+	}
+
+	if m.marks.renaming {
+		lines = append(lines, "", " "+m.marks.input.View())
+	}
+
+	return m.styles.FileList.
+		Width(m.width).
+		Height(height).
+		Render(strings.Join(lines, "\n"))
+}
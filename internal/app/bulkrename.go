@@ -0,0 +1,167 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/fs"
+)
+
+// bulkRenameMsg is sent when the editor opened for a bulk rename exits
+type bulkRenameMsg struct {
+	file    string   // Temporary file holding the names
+	sources []string // Paths in the order they were written
+	err     error
+}
+
+// bulkRename writes the selected (or all listed) names to a temporary file
+// and opens it in the user's editor; each line renames the matching file
+func (m Model) bulkRename() (Model, tea.Cmd) {
+	var sources []string
+	if len(m.selected) > 0 {
+		for path := range m.selected {
+			sources = append(sources, path)
+		}
+		sort.Strings(sources)
+	} else {
+		for _, file := range m.files {
+			sources = append(sources, file.Path)
+		}
+	}
+	if len(sources) == 0 {
+		return m, nil
+	}
+
+	tmp, err := os.CreateTemp("", "sushi-rename-*.txt")
+	if err != nil {
+		return m.fail(err), nil
+	}
+	var lines strings.Builder
+	for _, path := range sources {
+		if strings.ContainsAny(path, "\r\n") {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return m.fail(fmt.Errorf("cannot bulk rename %q: name contains a newline", filepath.Base(path))), nil
+		}
+		lines.WriteString(m.displayPath(path) + "\n")
+	}
+	_, err = tmp.WriteString(lines.String())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return m.fail(err), nil
+	}
+
+	file := tmp.Name()
+	return m, tea.ExecProcess(editorCommand(file), func(err error) tea.Msg {
+		return bulkRenameMsg{file: file, sources: sources, err: err}
+	})
+}
+
+// handleBulkRename reads back the edited names and asks for confirmation
+func (m Model) handleBulkRename(msg bulkRenameMsg) (Model, tea.Cmd) {
+	defer os.Remove(msg.file)
+	if msg.err != nil {
+		return m.fail(fmt.Errorf("editor: %w", msg.err)), nil
+	}
+
+	data, err := os.ReadFile(msg.file)
+	if err != nil {
+		return m.fail(err), nil
+	}
+	names := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(names) != len(msg.sources) {
+		return m.fail(fmt.Errorf("expected %d lines, got %d; nothing renamed", len(msg.sources), len(names))), nil
+	}
+
+	var renames []fs.Rename
+	var details []string
+	for i, name := range names {
+		name = strings.TrimSuffix(name, "\r")
+		from := msg.sources[i]
+		if name == m.displayPath(from) {
+			continue
+		}
+		to := ""
+		if strings.TrimSpace(name) != "" {
+			to = m.resolvePath(name)
+		}
+		if to == from {
+			continue
+		}
+		renames = append(renames, fs.Rename{From: from, To: to})
+		details = append(details, fmt.Sprintf("%s → %s", m.displayPath(from), name))
+	}
+	if len(renames) == 0 {
+		m.statusMsg = "No names changed"
+		return m, nil
+	}
+
+	plan, err := fs.PlanRenames(renames)
+	if err != nil {
+		return m.fail(err), nil
+	}
+
+	question := fmt.Sprintf("Rename %d item(s)", len(renames))
+	if plan.Cycles > 0 {
+		question += fmt.Sprintf(" (%d swap cycle(s))", plan.Cycles)
+	}
+	m, cmd := m.askConfirm(question+"?", func(m Model) (Model, tea.Cmd) {
+		return m.applyRenames(plan)
+	})
+	m.confirm.details = details
+	return m, cmd
+}
+
+// applyRenames performs a confirmed bulk rename as one journaled operation
+func (m Model) applyRenames(plan fs.RenamePlan) (Model, tea.Cmd) {
+	if len(m.jobs) > 0 {
+		m.statusMsg = "Wait for running jobs to finish first"
+		return m, nil
+	}
+
+	steps, err := fs.ApplyRenames(plan)
+	if err != nil {
+		return m.fail(err), m.refresh()
+	}
+
+	m = m.record(fs.OpRename, steps)
+	m = m.clearSelection()
+	m.statusMsg = fmt.Sprintf("Renamed %d item(s)", len(plan.Renames))
+
+	focus := ""
+	if path := m.cursorPath(); path != "" {
+		focus = path
+		for _, r := range plan.Renames {
+			if r.From == path {
+				focus = r.To
+			}
+		}
+	}
+	return m, m.loadDirectoryAt(m.currentPath, focus)
+}
+
+// displayPath shows path relative to the current directory when inside it
+func (m Model) displayPath(path string) string {
+	rel, err := filepath.Rel(m.currentPath, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
+
+// editorCommand builds the command editing file with $VISUAL or $EDITOR
+func editorCommand(file string) *exec.Cmd {
+	args, err := splitArgs(editor())
+	if err != nil || len(args) == 0 {
+		args = []string{"vi"}
+	}
+	return exec.Command(args[0], append(args[1:], file)...)
+}
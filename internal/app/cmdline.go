@@ -0,0 +1,193 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commandLine holds the : prompt, active in ModeCommand
+type commandLine struct {
+	input textinput.Model
+
+	// History of executed lines, oldest first
+	history      []string
+	historyIndex int    // len(history) while editing a new line
+	draft        string // The new line, kept while browsing history
+
+	// Tab completion cycle, reset by any other key
+	completions     []string
+	completionIndex int
+	completionBase  string // Text before the completed word
+}
+
+// maxCommandHistory caps the number of remembered command lines
+const maxCommandHistory = 100
+
+// newCommandLine creates an empty : prompt
+func newCommandLine() commandLine {
+	input := textinput.New()
+	input.Prompt = ":"
+	return commandLine{input: input}
+}
+
+// startCommandLine enters ModeCommand with an empty prompt
+func (m Model) startCommandLine() (Model, tea.Cmd) {
+	m.mode = ModeCommand
+	m.cmdline.input.SetValue("")
+	m.cmdline.historyIndex = len(m.cmdline.history)
+	m.cmdline.draft = ""
+	m.cmdline.completions = nil
+	return m, m.cmdline.input.Focus()
+}
+
+// handleCommandKey processes keyboard input on the : prompt
+func (m Model) handleCommandKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := &m.cmdline
+
+	switch {
+	case msg.Type == tea.KeyTab:
+		return m.completeCommand(1), nil
+	case msg.Type == tea.KeyShiftTab:
+		return m.completeCommand(-1), nil
+	}
+	c.completions = nil
+
+	switch {
+	case msg.Type == tea.KeyEsc:
+		m.mode = ModeNormal
+		c.input.Blur()
+		return m, nil
+
+	case msg.Type == tea.KeyEnter:
+		line := strings.TrimSpace(c.input.Value())
+		m.mode = ModeNormal
+		c.input.Blur()
+		if line == "" {
+			return m, nil
+		}
+		if n := len(c.history); n == 0 || c.history[n-1] != line {
+			c.history = append(c.history, line)
+			if len(c.history) > maxCommandHistory {
+				c.history = c.history[1:]
+			}
+		}
+		return m.execute(line)
+
+	case msg.Type == tea.KeyUp:
+		if c.historyIndex > 0 {
+			if c.historyIndex == len(c.history) {
+				c.draft = c.input.Value()
+			}
+			c.historyIndex--
+			c.input.SetValue(c.history[c.historyIndex])
+			c.input.CursorEnd()
+		}
+		return m, nil
+
+	case msg.Type == tea.KeyDown:
+		if c.historyIndex < len(c.history) {
+			c.historyIndex++
+			if c.historyIndex == len(c.history) {
+				c.input.SetValue(c.draft)
+			} else {
+				c.input.SetValue(c.history[c.historyIndex])
+			}
+			c.input.CursorEnd()
+		}
+		return m, nil
+
+	case msg.Type == tea.KeyBackspace && c.input.Value() == "":
+		// Backspace on an empty prompt closes it, like in vim
+		m.mode = ModeNormal
+		c.input.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	c.input, cmd = c.input.Update(msg)
+	return m, cmd
+}
+
+// completeCommand completes the word being typed, cycling through the
+// candidates on repeated presses
+func (m Model) completeCommand(step int) Model {
+	c := &m.cmdline
+
+	if c.completions == nil {
+		line := c.input.Value()
+		start := lastWordStart(line)
+		prefix := ""
+		if words, err := splitArgs(line[start:]); err == nil && len(words) > 0 {
+			prefix = words[0]
+		}
+		args, _ := splitArgs(line[:start])
+
+		var candidates []string
+		if len(args) == 0 {
+			candidates = filterPrefix(commandNames(), prefix)
+		} else if cmd, ok := lookupCommand(args[0]); ok && cmd.complete != nil {
+			candidates = cmd.complete(m, len(args)-1, prefix)
+		}
+		if len(candidates) == 0 {
+			m.statusMsg = "No completions"
+			return m
+		}
+
+		c.completions = candidates
+		c.completionBase = line[:start]
+		c.completionIndex = -1
+		if step < 0 {
+			c.completionIndex = 0
+		}
+	}
+
+	n := len(c.completions)
+	c.completionIndex = (c.completionIndex + step + n) % n
+	candidate := escapeArg(c.completions[c.completionIndex])
+
+	// A unique, complete word gets a trailing space to start the next one
+	if n == 1 && !strings.HasSuffix(candidate, string(filepath.Separator)) {
+		candidate += " "
+	}
+	c.input.SetValue(c.completionBase + candidate)
+	c.input.CursorEnd()
+	return m
+}
+
+// renderCommandLine renders the prompt and the completion position
+func (m Model) renderCommandLine() string {
+	view := m.cmdline.input.View()
+	if n := len(m.cmdline.completions); n > 1 {
+		view += fmt.Sprintf("  [%d/%d]", m.cmdline.completionIndex+1, n)
+	}
+	return view
+}
+
+// lastWordStart returns the byte offset where the last, possibly empty,
+// word of line begins
+func lastWordStart(line string) int {
+	start := 0
+	var quote rune
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ' ' || r == '\t':
+			start = i + 1
+		}
+	}
+	return start
+}
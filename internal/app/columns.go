@@ -0,0 +1,153 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/icichainz/sushi/internal/fs"
+	"github.com/icichainz/sushi/internal/ui"
+)
+
+// listingCacheSize bounds how many directory listings are kept in memory
+const listingCacheSize = 128
+
+// listingCache keeps recently scanned directories, so the parent column
+// can be drawn and h/l show a known directory without waiting for a scan.
+// It is shared by all tabs.
+type listingCache struct {
+	entries map[string][]fs.FileInfo
+	order   []string // Oldest first
+}
+
+// newListingCache creates an empty cache
+func newListingCache() *listingCache {
+	return &listingCache{entries: make(map[string][]fs.FileInfo)}
+}
+
+// get returns a copy of the cached listing of path
+func (c *listingCache) get(path string) ([]fs.FileInfo, bool) {
+	files, ok := c.entries[path]
+	return slices.Clone(files), ok
+}
+
+// put stores the listing of path, evicting the oldest one when full
+func (c *listingCache) put(path string, files []fs.FileInfo) {
+	if _, ok := c.entries[path]; ok {
+		c.order = slices.DeleteFunc(c.order, func(p string) bool { return p == path })
+	} else if len(c.order) >= listingCacheSize {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.entries[path] = slices.Clone(files)
+	c.order = append(c.order, path)
+}
+
+// listingLoadedMsg is sent when a directory shown beside the current one
+// has been scanned
+type listingLoadedMsg struct {
+	path  string
+	files []fs.FileInfo
+	err   error
+}
+
+// loadListing scans a directory for the listing cache
+func loadListing(path string) tea.Cmd {
+	return func() tea.Msg {
+		files, err := fs.ScanDirectory(path)
+		return listingLoadedMsg{path: path, files: files, err: err}
+	}
+}
+
+// loadParentColumn scans the parent of the current directory when the
+// columns layout needs it
+func (m Model) loadParentColumn() tea.Cmd {
+	parent := filepath.Dir(m.currentPath)
+	if m.layout != layoutColumns || parent == m.currentPath {
+		return nil
+	}
+	return loadListing(parent)
+}
+
+// enterDir shows path at once if its listing is cached, and scans it
+// either way so the listing catches up with the disk. With physical paths
+// on, symlinks in path are resolved first.
+func (m Model) enterDir(path, focus string) (Model, tea.Cmd) {
+	if m.physical {
+		if real, err := filepath.EvalSymlinks(path); err == nil && real != path {
+			if focus != "" && filepath.Dir(focus) == path {
+				focus = filepath.Join(real, filepath.Base(focus))
+			}
+			path = real
+		}
+	}
+
+	files, ok := m.listings.get(path)
+	if !ok {
+		return m, m.loadDirectoryAt(path, focus)
+	}
+
+	load := m.loadDirectoryAt(path, focus)
+	m = m.applyListing(dirLoadedMsg{path: path, files: files, focus: focus})
+	m.err = nil
+	return m, tea.Batch(load, m.loadParentColumn(), m.previewCursor())
+}
+
+// renderColumnsView renders the parent directory, the current one and the
+// preview side by side, ranger style
+func (m Model) renderColumnsView() string {
+	parentWidth := m.width / 6
+	rest := m.width - parentWidth
+	listWidth := rest
+	if m.previewEnabled {
+		listWidth = rest * m.previewWidth / 100
+	}
+
+	columns := []string{
+		m.renderParentColumn(parentWidth),
+		m.renderFileList(listWidth),
+	}
+	if m.previewEnabled {
+		columns = append(columns, m.renderPreview(rest-listWidth))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+}
+
+// renderParentColumn renders the names in the parent directory with the
+// current one highlighted
+func (m Model) renderParentColumn(width int) string {
+	height := m.height - 4
+	column := lipgloss.NewStyle().Width(width).MaxWidth(width).Height(height)
+
+	parent := filepath.Dir(m.currentPath)
+	files, ok := m.listings.get(parent)
+	if parent == m.currentPath || !ok {
+		return column.Render("")
+	}
+	fs.SortFiles(files, m.orderFor(parent))
+	files = slices.DeleteFunc(files, func(f fs.FileInfo) bool {
+		return f.Path != m.currentPath && m.isHidden(f)
+	})
+
+	current := slices.IndexFunc(files, func(f fs.FileInfo) bool { return f.Path == m.currentPath })
+	start := max(0, min(current-height/2, len(files)-height))
+	end := min(len(files), start+height)
+
+	lines := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		style := m.styles.File
+		if i == current {
+			style = m.styles.SelectedFile
+		}
+		if files[i].IsDir {
+			style = style.Foreground(lipgloss.Color("12"))
+		}
+		line := fmt.Sprintf(" %s %s", ui.GetFileIcon(files[i]), files[i].Name)
+		line += strings.Repeat(" ", max(0, width-lipgloss.Width(line)))
+		lines = append(lines, style.MaxWidth(width).Render(line))
+	}
+	return column.Render(strings.Join(lines, "\n"))
+}
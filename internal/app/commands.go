@@ -0,0 +1,753 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/fs"
+	"github.com/icichainz/sushi/internal/ui/components"
+)
+
+// command is a named action that can be run from the : prompt or bound
+// to a key
+type command struct {
+	name     string
+	usage    string // Argument synopsis
+	help     string
+	group    string // Category in the help overlay, set by register
+	minArgs  int
+	complete completer
+	run      func(m Model, args []string) (Model, tea.Cmd)
+}
+
+// completer returns the candidates for argument number arg that start
+// with prefix
+type completer func(m Model, arg int, prefix string) []string
+
+// commands is the registry of named commands
+var commands = map[string]*command{}
+
+// commandAliases maps short names to registered commands
+var commandAliases = map[string]string{
+	"q":  "quit",
+	"mv": "rename",
+}
+
+// commandGroups lists the command categories in registration order
+var commandGroups []string
+
+// register adds a group of commands to the registry
+func register(group string, cmds ...command) {
+	commandGroups = append(commandGroups, group)
+	for i := range cmds {
+		cmds[i].group = group
+		commands[cmds[i].name] = &cmds[i]
+	}
+}
+
+// lookupCommand finds a command by name or alias
+func lookupCommand(name string) (*command, bool) {
+	if alias, ok := commandAliases[name]; ok {
+		name = alias
+	}
+	cmd, ok := commands[name]
+	return cmd, ok
+}
+
+// commandNames returns all command names and aliases, sorted
+func commandNames() []string {
+	names := make([]string, 0, len(commands)+len(commandAliases))
+	for name := range commands {
+		names = append(names, name)
+	}
+	for alias := range commandAliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	register("General",
+		command{name: "quit", help: "quit sushi", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.quit()
+		}},
+		command{name: "pick", help: "pick the marked entries or the one under the cursor and quit", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.pick()
+		}},
+		command{name: "command-line", usage: "[text]", help: "open the : prompt, starting with text", run: func(m Model, args []string) (Model, tea.Cmd) {
+			m, cmd := m.startCommandLine()
+			if len(args) > 0 {
+				m.cmdline.input.SetValue(strings.Join(args, " ") + " ")
+				m.cmdline.input.CursorEnd()
+			}
+			return m, cmd
+		}},
+		command{name: "help", help: "show the key bindings", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.openHelp()
+		}},
+	)
+
+	register("Navigation",
+		command{name: "up", usage: "[count]", help: "move up", run: func(m Model, args []string) (Model, tea.Cmd) {
+			return m.moveCursor(-countArg(args))
+		}},
+		command{name: "down", usage: "[count]", help: "move down", run: func(m Model, args []string) (Model, tea.Cmd) {
+			return m.moveCursor(countArg(args))
+		}},
+		command{name: "top", help: "go to the first entry", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.moveCursor(-m.cursor)
+		}},
+		command{name: "bottom", help: "go to the last entry", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.moveCursor(len(m.files) - 1 - m.cursor)
+		}},
+		command{name: "parent", help: "go to the parent directory", run: cmdParent},
+		command{name: "open", help: "open the file under the cursor", run: cmdOpen},
+		command{name: "open-with", usage: "[opener]", help: "choose how to open the file under the cursor", complete: completeOpeners, run: cmdOpenWith},
+		command{name: "cd", usage: "<dir>", help: "change directory", minArgs: 1, complete: completePaths(true), run: cmdCd},
+		command{name: "back", usage: "[count]", help: "go back in the directory history", run: func(m Model, args []string) (Model, tea.Cmd) {
+			return m.historyJump(-countArg(args))
+		}},
+		command{name: "forward", usage: "[count]", help: "go forward in the directory history", run: func(m Model, args []string) (Model, tea.Cmd) {
+			return m.historyJump(countArg(args))
+		}},
+		command{name: "jumps", help: "list the directory history", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.jumpList()
+		}},
+		command{name: "z", usage: "[keywords...]", help: "jump to the most frecent directory matching keywords", complete: completeJump, run: func(m Model, args []string) (Model, tea.Cmd) {
+			return m.frecencyJump(args)
+		}},
+		command{name: "z-import", usage: "<zoxide|autojump|z> [file]", help: "import the directories ranked by another jumper", minArgs: 1, complete: completeImport, run: func(m Model, args []string) (Model, tea.Cmd) {
+			path := ""
+			if len(args) > 1 {
+				path = m.resolvePath(args[1])
+			}
+			return m.importFrecency(args[0], path)
+		}},
+	)
+
+	register("Bookmarks",
+		command{name: "mark", usage: "<key>", help: "bookmark this directory under a letter or digit", minArgs: 1, run: cmdMark},
+		command{name: "jump-mark", usage: "<key>", help: "go to the directory bookmarked under a key", minArgs: 1, run: func(m Model, args []string) (Model, tea.Cmd) {
+			return m.jumpToMark(args[0])
+		}},
+		command{name: "bookmark", usage: "[name]", help: "bookmark this directory", run: func(m Model, args []string) (Model, tea.Cmd) {
+			return m.addBookmark(strings.Join(args, " "))
+		}},
+		command{name: "bookmarks", help: "manage the bookmarks", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.openBookmarks()
+		}},
+	)
+
+	register("Tabs",
+		command{name: "tab-new", usage: "[dir]", help: "open a new tab", complete: completePaths(true), run: cmdTabNew},
+		command{name: "tab-close", help: "close the current tab", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.closeTab()
+		}},
+		command{name: "tab-next", help: "switch to the next tab", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.switchTab((m.activeTab + 1) % len(m.tabs))
+		}},
+		command{name: "tab-prev", help: "switch to the previous tab", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.switchTab((m.activeTab + len(m.tabs) - 1) % len(m.tabs))
+		}},
+		command{name: "tab", usage: "<n>", help: "switch to tab n", minArgs: 1, run: cmdTab},
+		command{name: "switch-pane", help: "focus the other pane, or the next tab", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.switchPane()
+		}},
+	)
+
+	register("File operations",
+		command{name: "mkdir", usage: "<name>", help: "create a directory", minArgs: 1, complete: completePaths(true), run: cmdMkdir},
+		command{name: "touch", usage: "<name>", help: "create a file or update its time", minArgs: 1, complete: completePaths(false), run: cmdTouch},
+		command{name: "rename", usage: "<new name>", help: "rename the file under the cursor", minArgs: 1, complete: completeCursorName, run: cmdRename},
+		command{name: "bulk-rename", help: "rename the selected or listed files in $EDITOR", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.bulkRename()
+		}},
+		command{name: "yank", help: "copy files to the clipboard", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.yank(false)
+		}},
+		command{name: "cut", help: "cut files to the clipboard", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.yank(true)
+		}},
+		command{name: "copy", usage: "[dir]", help: "copy files to dir, or to the other pane", complete: completePaths(true), run: func(m Model, args []string) (Model, tea.Cmd) {
+			return m.transferTo(jobCopy, args)
+		}},
+		command{name: "move", usage: "[dir]", help: "move files to dir, or to the other pane", complete: completePaths(true), run: func(m Model, args []string) (Model, tea.Cmd) {
+			return m.transferTo(jobMove, args)
+		}},
+		command{name: "paste", help: "paste the clipboard here", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.paste()
+		}},
+		command{name: "trash", help: "move files to the trash", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.trashTargets()
+		}},
+		command{name: "delete", help: "delete files permanently", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.confirmDelete()
+		}},
+		command{name: "trash-browser", help: "browse the trash", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.openTrash()
+		}},
+		command{name: "undo", help: "undo the last file operation", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.undo()
+		}},
+		command{name: "redo", help: "redo the last undone operation", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.redo()
+		}},
+	)
+
+	register("Searching",
+		command{name: "search", help: "filter the current directory", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.startSearch()
+		}},
+		command{name: "search-next", help: "jump to the next match", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.jumpMatch(1)
+		}},
+		command{name: "search-prev", help: "jump to the previous match", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.jumpMatch(-1)
+		}},
+		command{name: "clear-search", help: "clear the filter", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			if m.filter == "" {
+				return m, nil
+			}
+			return m.clearFilter()
+		}},
+		command{name: "find", help: "find a file below the current directory", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.openFinder()
+		}},
+	)
+
+	register("Selection",
+		command{name: "toggle-select", help: "mark or unmark the file under the cursor", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.toggleSelect()
+		}},
+		command{name: "visual", help: "start or end a range selection", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.toggleVisual()
+		}},
+		command{name: "select-all", help: "mark every listed file", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.selectAll()
+		}},
+		command{name: "invert-selection", help: "invert the marks in this directory", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.invertSelection()
+		}},
+		command{name: "select", usage: "<glob|/regex/>", help: "mark files matching a pattern", minArgs: 1, run: func(m Model, args []string) (Model, tea.Cmd) {
+			return m.selectPattern(args[0], true)
+		}},
+		command{name: "deselect", usage: "<glob|/regex/>", help: "unmark files matching a pattern", minArgs: 1, run: func(m Model, args []string) (Model, tea.Cmd) {
+			return m.selectPattern(args[0], false)
+		}},
+		command{name: "clear-selection", help: "unmark everything", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.clearSelection(), nil
+		}},
+		command{name: "cancel", help: "cancel the range selection, filter or marks", run: cmdCancel},
+	)
+
+	register("Tree",
+		command{name: "expand", help: "expand or collapse the directory under the cursor", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.toggleExpand()
+		}},
+		command{name: "collapse-all", help: "collapse every directory in the tree", run: func(m Model, _ []string) (Model, tea.Cmd) {
+			return m.collapseAll()
+		}},
+	)
+
+	register("Settings",
+		command{name: "sort", usage: "<key|reverse|dirs-first|reset>...", help: "change and remember the sort order of this directory", minArgs: 1, complete: completeSort, run: cmdSort},
+		command{name: "set", usage: "<option> [value]", help: "change an option, toggling booleans without a value", minArgs: 1, complete: completeSet, run: cmdSet},
+		command{name: "theme", usage: "<name>", help: "change the syntax highlighting theme", minArgs: 1, complete: completeWords(components.SyntaxThemes()...), run: cmdTheme},
+	)
+}
+
+// execute parses and runs a command line
+func (m Model) execute(line string) (Model, tea.Cmd) {
+	args, err := splitArgs(line)
+	if err != nil {
+		return m.fail(err), nil
+	}
+	if len(args) == 0 {
+		return m, nil
+	}
+
+	cmd, ok := lookupCommand(args[0])
+	if !ok {
+		return m.fail(fmt.Errorf("unknown command: %s", args[0])), nil
+	}
+	if len(args)-1 < cmd.minArgs {
+		return m.fail(fmt.Errorf("usage: %s %s", cmd.name, cmd.usage)), nil
+	}
+	return cmd.run(m, args[1:])
+}
+
+// fail reports an error in the status bar
+func (m Model) fail(err error) Model {
+	m.statusMsg = ""
+	m.err = err
+	return m
+}
+
+// resolvePath expands ~ and makes path absolute relative to currentPath
+func (m Model) resolvePath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.currentPath, path)
+	}
+	return filepath.Clean(path)
+}
+
+// countArg parses an optional repeat count, defaulting to 1
+func countArg(args []string) int {
+	if len(args) == 0 {
+		return 1
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+func cmdParent(m Model, _ []string) (Model, tea.Cmd) {
+	parentPath := filepath.Dir(m.currentPath)
+	if parentPath == m.currentPath {
+		return m, nil
+	}
+	return m.enterDir(parentPath, m.currentPath)
+}
+
+func cmdOpen(m Model, _ []string) (Model, tea.Cmd) {
+	if len(m.files) == 0 {
+		return m, nil
+	}
+	file := m.files[m.cursor]
+	if file.Broken {
+		return m.fail(fmt.Errorf("cannot open %s, a %s", file.Name, file.Description())), nil
+	}
+	if file.IsDir {
+		return m.enterDir(file.Path, "")
+	}
+	return m.openFile(file)
+}
+
+func cmdOpenWith(m Model, args []string) (Model, tea.Cmd) {
+	if len(args) == 0 || len(m.files) == 0 {
+		return m.openWithMenu()
+	}
+	file := m.files[m.cursor]
+	name := strings.Join(args, " ")
+	for _, opener := range m.openersFor(file) {
+		if opener.Name == name {
+			return m.openWith(file, opener)
+		}
+	}
+	return m.fail(fmt.Errorf("no opener %q for %s", name, file.Name)), nil
+}
+
+func cmdMark(m Model, args []string) (Model, tea.Cmd) {
+	if !isCharKey(args[0]) {
+		return m.fail(fmt.Errorf("bookmark keys are a single letter or digit")), nil
+	}
+	return m.setMark(args[0])
+}
+
+func cmdCd(m Model, args []string) (Model, tea.Cmd) {
+	path := m.resolvePath(args[0])
+	info, err := os.Stat(path)
+	if err != nil {
+		return m.fail(err), nil
+	}
+	if !info.IsDir() {
+		return m.fail(fmt.Errorf("not a directory: %s", args[0])), nil
+	}
+	return m.enterDir(path, "")
+}
+
+func cmdMkdir(m Model, args []string) (Model, tea.Cmd) {
+	path := m.resolvePath(args[0])
+	if err := os.Mkdir(path, 0o755); err != nil {
+		return m.fail(err), nil
+	}
+
+	m = m.record(fs.OpMkdir, []fs.Step{fs.NewStep("", path)})
+	m.statusMsg = "Created " + filepath.Base(path)
+	return m, m.loadDirectoryAt(filepath.Dir(path), path)
+}
+
+func cmdTouch(m Model, args []string) (Model, tea.Cmd) {
+	path := m.resolvePath(args[0])
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	switch {
+	case err == nil:
+		f.Close()
+		m.statusMsg = "Created " + filepath.Base(path)
+	case errors.Is(err, os.ErrExist):
+		now := time.Now()
+		if err := os.Chtimes(path, now, now); err != nil {
+			return m.fail(err), nil
+		}
+		m.statusMsg = "Touched " + filepath.Base(path)
+	default:
+		return m.fail(err), nil
+	}
+	return m, m.loadDirectoryAt(filepath.Dir(path), path)
+}
+
+func cmdRename(m Model, args []string) (Model, tea.Cmd) {
+	if len(m.files) == 0 {
+		return m, nil
+	}
+	from := m.files[m.cursor].Path
+	to := filepath.Join(filepath.Dir(from), args[0])
+	if strings.ContainsRune(args[0], filepath.Separator) {
+		to = m.resolvePath(args[0])
+	}
+	if to == from {
+		return m, nil
+	}
+	if _, err := os.Lstat(to); err == nil {
+		return m.fail(fmt.Errorf("%s already exists", args[0])), nil
+	}
+	if err := os.Rename(from, to); err != nil {
+		return m.fail(err), nil
+	}
+
+	m = m.record(fs.OpRename, []fs.Step{fs.NewStep(from, to)})
+	m.statusMsg = fmt.Sprintf("Renamed %s to %s", filepath.Base(from), filepath.Base(to))
+	return m, m.loadDirectoryAt(m.currentPath, to)
+}
+
+func cmdCancel(m Model, _ []string) (Model, tea.Cmd) {
+	switch {
+	case m.mode == ModeVisual:
+		return m.cancelVisual(), nil
+	case m.filter != "":
+		return m.clearFilter()
+	case len(m.selected) > 0:
+		m.statusMsg = fmt.Sprintf("Cleared %d marks", len(m.selected))
+		return m.clearSelection(), nil
+	}
+	return m, nil
+}
+
+func cmdSort(m Model, args []string) (Model, tea.Cmd) {
+	order, remember := m.order, true
+	for _, arg := range args {
+		switch arg {
+		case "reset":
+			order, remember = m.defaultOrder, false
+			continue
+		case "reverse":
+			order.Reverse = !order.Reverse
+		case "dirs-first":
+			order.DirsFirst = !order.DirsFirst
+		default:
+			by, err := fs.ParseSortKey(arg)
+			if err != nil {
+				return m.fail(err), nil
+			}
+			order.By = by
+		}
+		remember = true
+	}
+
+	// The order sticks to the directory
+	var err error
+	if remember {
+		err = m.sortOrders.set(m.currentPath, order)
+	} else {
+		err = m.sortOrders.forget(m.currentPath)
+	}
+
+	m.order = order
+	m = m.setFiles(m.allFiles)
+	if err != nil {
+		return m.fail(err), m.previewCursor()
+	}
+	m.statusMsg = "Sorted by " + order.String()
+	if !remember {
+		m.statusMsg += " (default)"
+	}
+	return m, m.previewCursor()
+}
+
+// options lists the names accepted by :set
+var options = []string{"preview", "syntax", "split", "layout", "tree", "hidden", "gitignore", "physical"}
+
+func cmdSet(m Model, args []string) (Model, tea.Cmd) {
+	name, value := args[0], ""
+	if len(args) > 1 {
+		value = args[1]
+	}
+
+	switch name {
+	case "preview":
+		enabled, err := boolOption(value, m.previewEnabled)
+		if err != nil {
+			return m.fail(err), nil
+		}
+		m.previewEnabled = enabled
+		m.statusMsg = "Preview " + onOff(enabled)
+		return m, m.previewCursor()
+
+	case "syntax":
+		enabled, err := boolOption(value, m.syntaxHighlight)
+		if err != nil {
+			return m.fail(err), nil
+		}
+		m.syntaxHighlight = enabled
+		m.statusMsg = "Syntax highlighting " + onOff(enabled)
+		// Reload current preview with new setting
+		return m, m.previewCursor()
+
+	case "split":
+		percent, err := strconv.Atoi(value)
+		if err != nil || percent < 10 || percent > 90 {
+			return m.fail(fmt.Errorf("split must be a percentage between 10 and 90")), nil
+		}
+		m.previewWidth = percent
+		m.statusMsg = fmt.Sprintf("File list uses %d%% of the width", percent)
+		return m, nil
+
+	case "tree":
+		enabled, err := boolOption(value, m.treeView)
+		if err != nil {
+			return m.fail(err), nil
+		}
+		return m.setTreeView(enabled)
+
+	case "hidden":
+		show, err := boolOption(value, m.showHidden)
+		if err != nil {
+			return m.fail(err), nil
+		}
+		return m.setShowHidden(show)
+
+	case "gitignore":
+		enabled, err := boolOption(value, m.gitignore)
+		if err != nil {
+			return m.fail(err), nil
+		}
+		return m.setGitIgnore(enabled)
+
+	case "physical":
+		enabled, err := boolOption(value, m.physical)
+		if err != nil {
+			return m.fail(err), nil
+		}
+		m.physical = enabled
+		m.statusMsg = "Physical paths " + onOff(enabled)
+		if enabled {
+			// Move to where the symlinks followed so far lead
+			real, err := filepath.EvalSymlinks(m.currentPath)
+			if err == nil && real != m.currentPath {
+				focus := ""
+				if path := m.cursorPath(); path != "" {
+					focus = filepath.Join(real, filepath.Base(path))
+				}
+				return m.enterDir(real, focus)
+			}
+		}
+		return m, nil
+
+	case "layout":
+		layout, err := parseLayout(value, m.layout)
+		if err != nil {
+			return m.fail(err), nil
+		}
+		return m.setLayout(layout)
+	}
+
+	return m.fail(fmt.Errorf("unknown option: %s", name)), nil
+}
+
+func cmdTheme(m Model, args []string) (Model, tea.Cmd) {
+	if !components.HasSyntaxTheme(args[0]) {
+		return m.fail(fmt.Errorf("unknown theme: %s", args[0])), nil
+	}
+	m.syntaxTheme = args[0]
+	m.statusMsg = "Theme " + args[0]
+	return m, m.previewCursor()
+}
+
+// boolOption parses a boolean option value, toggling current when empty
+func boolOption(value string, current bool) (bool, error) {
+	switch value {
+	case "":
+		return !current, nil
+	case "true", "on", "yes":
+		return true, nil
+	case "false", "off", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("expected true or false, got %q", value)
+}
+
+func onOff(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+// completeWords completes from a fixed list of words
+func completeWords(words ...string) completer {
+	return func(_ Model, _ int, prefix string) []string {
+		return filterPrefix(words, prefix)
+	}
+}
+
+// completePaths completes file names relative to the current directory
+func completePaths(dirsOnly bool) completer {
+	return func(m Model, _ int, prefix string) []string {
+		dirPart, base := "", prefix
+		if i := strings.LastIndex(prefix, string(filepath.Separator)); i >= 0 {
+			dirPart, base = prefix[:i+1], prefix[i+1:]
+		}
+
+		entries, err := os.ReadDir(m.resolvePath(dirPart + "."))
+		if err != nil {
+			return nil
+		}
+
+		var candidates []string
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasPrefix(name, base) {
+				continue
+			}
+			// Only offer hidden entries when asked for
+			if strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+				continue
+			}
+			if entry.IsDir() {
+				candidates = append(candidates, dirPart+name+string(filepath.Separator))
+			} else if !dirsOnly {
+				candidates = append(candidates, dirPart+name)
+			}
+		}
+		return candidates
+	}
+}
+
+// completeCursorName offers the name of the file under the cursor
+func completeCursorName(m Model, _ int, prefix string) []string {
+	if len(m.files) == 0 {
+		return nil
+	}
+	return filterPrefix([]string{m.files[m.cursor].Name}, prefix)
+}
+
+func completeOpeners(m Model, arg int, prefix string) []string {
+	if arg != 0 || len(m.files) == 0 {
+		return nil
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, opener := range m.openersFor(m.files[m.cursor]) {
+		if !seen[opener.Name] {
+			seen[opener.Name] = true
+			names = append(names, opener.Name)
+		}
+	}
+	return filterPrefix(names, prefix)
+}
+
+func completeSort(_ Model, _ int, prefix string) []string {
+	words := []string{"reverse", "dirs-first", "reset"}
+	for _, key := range fs.SortKeys {
+		words = append(words, string(key))
+	}
+	return filterPrefix(words, prefix)
+}
+
+func completeSet(_ Model, arg int, prefix string) []string {
+	if arg == 0 {
+		return filterPrefix(options, prefix)
+	}
+	// Values of every option, since the completer doesn't see the name
+	values := []string{"true", "false"}
+	for _, l := range layouts {
+		values = append(values, string(l))
+	}
+	return filterPrefix(values, prefix)
+}
+
+// filterPrefix returns the words starting with prefix, sorted
+func filterPrefix(words []string, prefix string) []string {
+	var matches []string
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) {
+			matches = append(matches, word)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// splitArgs splits a command line into words, honouring quotes and
+// backslash escapes
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && quote != '\'' && i+1 < len(runes):
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
+
+// escapeArg escapes the characters splitArgs treats specially
+func escapeArg(arg string) string {
+	var b strings.Builder
+	for _, r := range arg {
+		switch r {
+		case ' ', '\t', '\\', '"', '\'':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
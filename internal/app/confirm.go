@@ -0,0 +1,57 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// confirmPrompt is a yes/no question shown in the status bar
+type confirmPrompt struct {
+	message    string
+	onConfirm  func(Model) (Model, tea.Cmd)
+	returnMode Mode     // Mode to go back to once answered
+	details    []string // Optional lines listed in place of the files
+}
+
+// askConfirm switches to ModeConfirm with the given question
+func (m Model) askConfirm(message string, onConfirm func(Model) (Model, tea.Cmd)) (Model, tea.Cmd) {
+	m.confirm = confirmPrompt{message: message, onConfirm: onConfirm, returnMode: m.mode}
+	m.mode = ModeConfirm
+	return m, nil
+}
+
+// handleConfirmKey answers the pending question: y or enter confirms,
+// anything else cancels
+func (m Model) handleConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	prompt := m.confirm
+	m.confirm = confirmPrompt{}
+	m.mode = prompt.returnMode
+
+	switch msg.String() {
+	case "y", "Y", "enter":
+		return prompt.onConfirm(m)
+	}
+
+	m.statusMsg = "Cancelled"
+	return m, nil
+}
+
+// renderConfirmDetails lists what the pending question is about
+func (m Model) renderConfirmDetails() string {
+	height := m.height - 4
+	lines := m.confirm.details
+	if height > 1 && len(lines) > height {
+		lines = append(lines[:height-1:height-1], fmt.Sprintf("… and %d more", len(m.confirm.details)-height+1))
+	}
+
+	rendered := make([]string, len(lines))
+	for i, line := range lines {
+		rendered[i] = m.styles.File.Render(line)
+	}
+	return m.styles.FileList.
+		Width(m.width).
+		Height(height).
+		Render(strings.Join(rendered, "\n"))
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package app
+
+import "os/exec"
+
+// detach is a no-op where sessions are not available
+func detach(cmd *exec.Cmd) {}
@@ -0,0 +1,329 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/icichainz/sushi/internal/fs"
+	"github.com/icichainz/sushi/internal/utils"
+)
+
+// finderState holds the recursive fuzzy finder, active in ModeFinder
+type finderState struct {
+	input      textinput.Model
+	root       string
+	candidates []string      // Paths relative to root, in discovery order
+	matches    []finderMatch // Candidates matching the query, best first
+	query      string        // Query the matches were computed for
+	scored     int           // Candidates the matches were computed from
+	cursor     int
+	walking    bool
+	gen        int // Identifies the current walk, stale batches are dropped
+	cancel     context.CancelFunc
+	batches    chan []string
+
+	// Query being ranked in the background, stale results are dropped
+	wanted      string
+	queryGen    int
+	cancelScore context.CancelFunc
+}
+
+// finderMatch is a candidate that matches the finder query
+type finderMatch struct {
+	index int
+	score int
+}
+
+// finderBatchMsg delivers newly discovered candidates
+type finderBatchMsg struct {
+	gen   int
+	paths []string
+	done  bool
+}
+
+// finderScoredMsg delivers the matches ranked for a query
+type finderScoredMsg struct {
+	gen      int
+	queryGen int
+	query    string
+	matches  []finderMatch
+	scored   int
+}
+
+// openFinder starts walking the tree below the current directory
+func (m Model) openFinder() (Model, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	batches := make(chan []string, 4)
+
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = "find file below " + filepath.Base(m.currentPath)
+
+	m.mode = ModeFinder
+	m.finder = finderState{
+		input:   input,
+		root:    m.currentPath,
+		walking: true,
+		gen:     m.finder.gen + 1,
+		cancel:  cancel,
+		batches: batches,
+	}
+
+	go fs.Walk(ctx, m.currentPath, m.walkOptions, batches)
+	return m, tea.Batch(m.finder.input.Focus(), waitForBatch(m.finder.gen, batches))
+}
+
+// closeFinder stops the walk and returns to the file list
+func (m Model) closeFinder() Model {
+	if m.finder.cancel != nil {
+		m.finder.cancel()
+	}
+	if m.finder.cancelScore != nil {
+		m.finder.cancelScore()
+	}
+	m.finder = finderState{gen: m.finder.gen}
+	m.mode = ModeNormal
+	return m
+}
+
+// waitForBatch waits for the next batch of candidates from a walk
+func waitForBatch(gen int, batches chan []string) tea.Cmd {
+	return func() tea.Msg {
+		paths, ok := <-batches
+		return finderBatchMsg{gen: gen, paths: paths, done: !ok}
+	}
+}
+
+// handleFinderBatch merges newly discovered candidates into the results
+func (m Model) handleFinderBatch(msg finderBatchMsg) (Model, tea.Cmd) {
+	if m.mode != ModeFinder || msg.gen != m.finder.gen {
+		return m, nil
+	}
+	if msg.done {
+		m.finder.walking = false
+		return m, nil
+	}
+
+	m.finder.candidates = append(m.finder.candidates, msg.paths...)
+	if m.finder.wanted == m.finder.query {
+		m.finder.scoreCandidates() // Otherwise done once the query is ranked
+	}
+	return m, waitForBatch(msg.gen, m.finder.batches)
+}
+
+// handleFinderScored shows the matches ranked for the latest query, then
+// scores the candidates discovered in the meantime
+func (m Model) handleFinderScored(msg finderScoredMsg) (Model, tea.Cmd) {
+	f := &m.finder
+	if m.mode != ModeFinder || msg.gen != f.gen || msg.queryGen != f.queryGen {
+		return m, nil
+	}
+
+	f.query, f.matches, f.scored = msg.query, msg.matches, msg.scored
+	f.cursor = 0
+	f.scoreCandidates()
+	return m, nil
+}
+
+// scoreCandidates matches the candidates discovered since the last scoring
+// against the query and merges them into the ranked results. Only the new
+// matches are sorted, so large trees don't re-sort everything on every
+// batch.
+func (f *finderState) scoreCandidates() {
+	start := f.scored
+	f.scored = len(f.candidates)
+
+	var found []finderMatch
+	for i := start; i < len(f.candidates); i++ {
+		if score, _, ok := utils.FuzzyMatch(f.query, f.candidates[i]); ok {
+			found = append(found, finderMatch{index: i, score: score})
+		}
+	}
+	if f.query == "" {
+		f.matches = append(f.matches, found...)
+		return
+	}
+
+	slices.SortFunc(found, func(a, b finderMatch) int { return compareMatches(f.candidates, a, b) })
+	merged := make([]finderMatch, 0, len(f.matches)+len(found))
+	i, j := 0, 0
+	for i < len(f.matches) && j < len(found) {
+		if f.better(found[j], f.matches[i]) {
+			merged = append(merged, found[j])
+			j++
+		} else {
+			merged = append(merged, f.matches[i])
+			i++
+		}
+	}
+	merged = append(merged, f.matches[i:]...)
+	f.matches = append(merged, found[j:]...)
+}
+
+// setQuery ranks the candidates for a new query in the background, as
+// large trees take too long to rank between two keys. The previous matches
+// are narrowed when the new query extends theirs, and stay on screen until
+// the new ones are ready.
+func (f *finderState) setQuery(query string) tea.Cmd {
+	if query == f.wanted {
+		return nil
+	}
+	if f.cancelScore != nil {
+		f.cancelScore()
+	}
+	f.wanted = query
+	f.queryGen++
+	if query == f.query {
+		f.scoreCandidates() // Back to the matches shown
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancelScore = cancel
+
+	var from []finderMatch
+	start := 0
+	if f.query != "" && strings.HasPrefix(query, f.query) {
+		from, start = f.matches, f.scored
+	}
+	gen, queryGen, candidates := f.gen, f.queryGen, f.candidates
+	return func() tea.Msg {
+		matches, ok := rankCandidates(ctx, query, candidates, from, start)
+		if !ok {
+			return nil
+		}
+		return finderScoredMsg{gen: gen, queryGen: queryGen, query: query, matches: matches, scored: len(candidates)}
+	}
+}
+
+// rankCandidates matches query against the candidates of from, then those
+// from index start onwards, and sorts the matches best first. It gives up
+// once ctx is cancelled.
+func rankCandidates(ctx context.Context, query string, candidates []string, from []finderMatch, start int) ([]finderMatch, bool) {
+	var matches []finderMatch
+	try := func(n, index int) bool {
+		if n%1024 == 0 && ctx.Err() != nil {
+			return false
+		}
+		if score, _, ok := utils.FuzzyMatch(query, candidates[index]); ok {
+			matches = append(matches, finderMatch{index: index, score: score})
+		}
+		return true
+	}
+
+	for n, match := range from {
+		if !try(n, match.index) {
+			return nil, false
+		}
+	}
+	for i := start; i < len(candidates); i++ {
+		if !try(i, i) {
+			return nil, false
+		}
+	}
+	if query != "" {
+		slices.SortFunc(matches, func(a, b finderMatch) int { return compareMatches(candidates, a, b) })
+	}
+	return matches, ctx.Err() == nil
+}
+
+// better reports whether match a ranks before match b
+func (f *finderState) better(a, b finderMatch) bool {
+	return compareMatches(f.candidates, a, b) < 0
+}
+
+// compareMatches orders matches by score, then by shorter path, then in
+// discovery order
+func compareMatches(candidates []string, a, b finderMatch) int {
+	if a.score != b.score {
+		return b.score - a.score
+	}
+	if n := len(candidates[a.index]) - len(candidates[b.index]); n != 0 {
+		return n
+	}
+	return a.index - b.index
+}
+
+// handleFinderKey processes keyboard input in the finder
+func (m Model) handleFinderKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyEsc:
+		return m.closeFinder(), nil
+
+	case msg.Type == tea.KeyEnter:
+		if len(m.finder.matches) == 0 {
+			return m, nil
+		}
+		rel := m.finder.candidates[m.finder.matches[m.finder.cursor].index]
+		target := filepath.Join(m.finder.root, rel)
+		m = m.closeFinder()
+		m.filter = ""
+		return m, m.loadDirectoryAt(filepath.Dir(target), target)
+
+	case msg.Type == tea.KeyUp, msg.Type == tea.KeyCtrlP, msg.Type == tea.KeyCtrlK:
+		m.finder.cursor = max(0, m.finder.cursor-1)
+		return m, nil
+
+	case msg.Type == tea.KeyDown, msg.Type == tea.KeyCtrlN, msg.Type == tea.KeyCtrlJ:
+		m.finder.cursor = min(len(m.finder.matches)-1, m.finder.cursor+1)
+		m.finder.cursor = max(0, m.finder.cursor)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.finder.input, cmd = m.finder.input.Update(msg)
+	return m, tea.Batch(cmd, m.finder.setQuery(m.finder.input.Value()))
+}
+
+// renderFinder renders the finder prompt and the best ranked matches
+func (m Model) renderFinder() string {
+	height := m.height - 4
+	f := m.finder
+
+	status := fmt.Sprintf("%d/%d", len(f.matches), len(f.candidates))
+	if f.walking {
+		status += " (scanning…)"
+	} else if f.wanted != f.query {
+		status += " (matching…)"
+	}
+	prompt := f.input.View()
+	gap := max(1, m.width-4-lipgloss.Width(prompt)-lipgloss.Width(status))
+	lines := []string{prompt + strings.Repeat(" ", gap) + m.styles.EmptyDir.Render(status), ""}
+
+	// Keep the cursor visible
+	rows := max(1, height-len(lines))
+	start := max(0, f.cursor-rows+1)
+	end := min(len(f.matches), start+rows)
+
+	for i := start; i < end; i++ {
+		path := f.candidates[f.matches[i].index]
+		style := m.styles.File
+		if i == f.cursor {
+			style = m.styles.SelectedFile
+		}
+
+		display := path
+		if maxLen := m.width - 4; maxLen > 3 {
+			display = truncateStart(path, maxLen, "...")
+		}
+		truncated := display != path
+		line := padRight(display, m.width-4)
+
+		if f.query != "" && !truncated {
+			_, positions, _ := utils.FuzzyMatch(f.query, path)
+			lines = append(lines, renderHighlighted(line, positions, style, m.styles.Match.Inherit(style)))
+		} else {
+			lines = append(lines, style.Render(line))
+		}
+	}
+
+	return m.styles.FileList.
+		Width(m.width).
+		Height(height).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"slices"
+	"testing"
+)
+
+// finderPaths returns the ranked matches of the finder
+func finderPaths(f finderState) []string {
+	paths := make([]string, len(f.matches))
+	for i, match := range f.matches {
+		paths[i] = f.candidates[match.index]
+	}
+	return paths
+}
+
+func TestFinderQuery(t *testing.T) {
+	m := Model{mode: ModeFinder}
+	m.finder.candidates = []string{"main.go", "cmd/", "cmd/main.go", "internal/app/model.go", "README.md"}
+	m.finder.scoreCandidates()
+
+	// Results for a query replaced before they arrive are dropped
+	stale := m.finder.setQuery("ma")().(finderScoredMsg)
+	fresh := m.finder.setQuery("mai")
+	if m.finder.query != "" || len(m.finder.matches) != len(m.finder.candidates) {
+		t.Fatalf("matches changed before being ranked: %q", finderPaths(m.finder))
+	}
+	m, _ = m.handleFinderScored(stale)
+	if m.finder.query != "" {
+		t.Fatalf("stale results for %q applied", m.finder.query)
+	}
+	m, _ = m.handleFinderScored(fresh().(finderScoredMsg))
+	want := []string{"main.go", "cmd/main.go"}
+	if got := finderPaths(m.finder); m.finder.query != "mai" || !slices.Equal(got, want) {
+		t.Fatalf("query %q matched %q, want %q", m.finder.query, got, want)
+	}
+
+	// Candidates found while ranking are merged once the results arrive
+	cmd := m.finder.setQuery("main")
+	m, _ = m.handleFinderBatch(finderBatchMsg{paths: []string{"docs/main.md"}})
+	if len(m.finder.matches) != 2 {
+		t.Fatalf("candidate scored for the old query: %q", finderPaths(m.finder))
+	}
+	m, _ = m.handleFinderScored(cmd().(finderScoredMsg))
+	want = []string{"main.go", "cmd/main.go", "docs/main.md"}
+	if got := finderPaths(m.finder); !slices.Equal(got, want) {
+		t.Fatalf("narrowed to %q, want %q", got, want)
+	}
+
+	// Going back to the query shown needs no ranking
+	if cmd := m.finder.setQuery("mainx"); cmd == nil {
+		t.Fatal("no ranking for a new query")
+	}
+	if cmd := m.finder.setQuery("main"); cmd != nil || m.finder.wanted != m.finder.query {
+		t.Fatal("ranking again the query shown")
+	}
+
+	// Narrowing gives the same ranking as scanning everything
+	narrowed := m.finder.setQuery("main.g")().(finderScoredMsg)
+	m.finder.query, m.finder.wanted = "", ""
+	full := m.finder.setQuery("main.g")().(finderScoredMsg)
+	if !slices.Equal(narrowed.matches, full.matches) {
+		t.Fatalf("narrowed to %v, scanned %v", narrowed.matches, full.matches)
+	}
+}
+
+func TestFinderQueryCancelled(t *testing.T) {
+	m := Model{mode: ModeFinder}
+	m.finder.candidates = make([]string, 5000)
+	for i := range m.finder.candidates {
+		m.finder.candidates[i] = "file"
+	}
+	cmd := m.finder.setQuery("f")
+	m = m.closeFinder()
+	if msg := cmd(); msg != nil {
+		t.Fatalf("cancelled ranking sent %T", msg)
+	}
+}
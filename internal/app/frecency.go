@@ -0,0 +1,110 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/frecency"
+	"github.com/icichainz/sushi/internal/utils"
+)
+
+// frecencyPath is where the visited directories are ranked
+func frecencyPath() string {
+	return filepath.Join(utils.DataDir(), "frecency")
+}
+
+// maxJumpChoices caps the directories offered by :z without keywords
+const maxJumpChoices = 30
+
+// jumpCandidates ranks the directories matching keywords, leaving out the
+// current one and forgetting those that no longer exist
+func (m Model) jumpCandidates(keywords []string, limit int) []string {
+	var paths []string
+	for _, e := range m.frecency.Query(keywords) {
+		if e.Path == m.currentPath {
+			continue
+		}
+		if info, err := os.Stat(e.Path); err != nil || !info.IsDir() {
+			m.frecency.Remove(e.Path)
+			continue
+		}
+		paths = append(paths, e.Path)
+		if len(paths) == limit {
+			break
+		}
+	}
+	return paths
+}
+
+// frecencyJump goes to the best ranked directory matching keywords, or
+// offers the top ones in a menu when there are none
+func (m Model) frecencyJump(keywords []string) (Model, tea.Cmd) {
+	if len(keywords) == 0 {
+		paths := m.jumpCandidates(nil, maxJumpChoices)
+		if len(paths) == 0 {
+			m.statusMsg = "No directories visited yet"
+			return m, nil
+		}
+		items := make([]string, len(paths))
+		for i, path := range paths {
+			items[i] = utils.ShortenPath(path)
+		}
+		return m.openMenu("Frequent directories", items, func(m Model, choice int) (Model, tea.Cmd) {
+			return m.enterDir(paths[choice], "")
+		})
+	}
+
+	// A completed path jumps straight there
+	if len(keywords) == 1 && filepath.IsAbs(keywords[0]) {
+		if info, err := os.Stat(keywords[0]); err == nil && info.IsDir() {
+			return m.enterDir(filepath.Clean(keywords[0]), "")
+		}
+	}
+
+	paths := m.jumpCandidates(keywords, 1)
+	if len(paths) == 0 {
+		return m.fail(fmt.Errorf("no visited directory matches %q", strings.Join(keywords, " "))), nil
+	}
+	return m.enterDir(paths[0], "")
+}
+
+// importFrecency merges another jumper's database into ours
+func (m Model) importFrecency(source, path string) (Model, tea.Cmd) {
+	entries, path, err := frecency.Read(source, path)
+	if err != nil && len(entries) == 0 {
+		return m.fail(err), nil
+	}
+	added, saveErr := m.frecency.Import(entries)
+	if saveErr != nil {
+		return m.fail(saveErr), nil
+	}
+
+	m.statusMsg = fmt.Sprintf("Imported %d entries (%d new) from %s", len(entries), added, utils.ShortenPath(path))
+	if err != nil {
+		m = m.fail(fmt.Errorf("imported %d entries, skipping some: %w", len(entries), err))
+	}
+	return m, nil
+}
+
+// completeJump completes :z keywords to the best ranked matching paths
+func completeJump(m Model, _ int, prefix string) []string {
+	if prefix == "" {
+		return nil
+	}
+	return m.jumpCandidates([]string{prefix}, 10)
+}
+
+// completeImport completes the names of importable databases, then paths
+func completeImport(m Model, arg int, prefix string) []string {
+	if arg == 0 {
+		names := make([]string, len(frecency.Sources))
+		for i, src := range frecency.Sources {
+			names[i] = src.Name
+		}
+		return filterPrefix(names, prefix)
+	}
+	return completePaths(false)(m, arg, prefix)
+}
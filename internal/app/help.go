@@ -0,0 +1,175 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// helpState holds the key binding overview, active in ModeHelp
+type helpState struct {
+	offset     int  // First visible line
+	returnMode Mode // Mode to go back to once closed
+}
+
+// helpRow is one line of the overview: every key running the same command
+type helpRow struct {
+	keys []string
+	help string
+}
+
+// openHelp shows the overview of the current key bindings
+func (m Model) openHelp() (Model, tea.Cmd) {
+	m.help = helpState{returnMode: m.mode}
+	m.mode = ModeHelp
+	return m, nil
+}
+
+// handleHelpKey scrolls the overview; esc, q or ? close it
+func (m Model) handleHelpKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	page := max(1, m.height-6)
+	last := max(0, len(m.helpLines())-(m.height-4))
+
+	switch msg.String() {
+	case "esc", "q", "?":
+		m.mode = m.help.returnMode
+		m.help = helpState{}
+		return m, nil
+	case "up", "k":
+		m.help.offset--
+	case "down", "j":
+		m.help.offset++
+	case "pgup", "ctrl+u", "ctrl+b":
+		m.help.offset -= page
+	case "pgdown", "ctrl+d", "ctrl+f", " ":
+		m.help.offset += page
+	case "home", "g":
+		m.help.offset = 0
+	case "end", "G":
+		m.help.offset = last
+	}
+	m.help.offset = max(0, min(last, m.help.offset))
+	return m, nil
+}
+
+// helpRows groups the live bindings by command category, merging keys
+// bound to the same command line
+func (m Model) helpRows() map[string][]helpRow {
+	rows := make(map[string][]helpRow)
+	index := make(map[string]int) // Row index by command line
+	for _, kb := range m.bindings.list {
+		group := "Other"
+		if args, err := splitArgs(kb.command); err == nil && len(args) > 0 {
+			if cmd, ok := lookupCommand(args[0]); ok {
+				group = cmd.group
+			}
+		}
+
+		if i, ok := index[kb.command]; ok {
+			rows[group][i].keys = append(rows[group][i].keys, kb.keys.String())
+			continue
+		}
+		index[kb.command] = len(rows[group])
+		rows[group] = append(rows[group], helpRow{
+			keys: []string{kb.keys.String()},
+			help: describeBinding(kb),
+		})
+	}
+	return rows
+}
+
+// describeBinding returns the help text of a binding: the default's own
+// description, else the command's, else the command line itself
+func describeBinding(kb keyBinding) string {
+	if kb.help != "" {
+		return kb.help
+	}
+	args, err := splitArgs(kb.command)
+	if err == nil && len(args) == 1 {
+		if cmd, ok := lookupCommand(args[0]); ok {
+			return cmd.help
+		}
+	}
+	return ":" + kb.command
+}
+
+// helpLines renders the whole overview, one string per line
+func (m Model) helpLines() []string {
+	rows := m.helpRows()
+	groups := append(append([]string{}, commandGroups...), "Other")
+
+	var lines []string
+	for _, group := range groups {
+		if len(rows[group]) == 0 {
+			continue
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, m.styles.Match.Render(group))
+		for _, row := range rows[group] {
+			keys := strings.Join(row.keys, ", ")
+			lines = append(lines, fmt.Sprintf("  %-16s %s", keys, row.help))
+		}
+	}
+	return lines
+}
+
+// renderHelp renders the visible part of the overview
+func (m Model) renderHelp() string {
+	height := m.height - 4
+	lines := m.helpLines()
+
+	start := min(m.help.offset, len(lines))
+	end := min(len(lines), start+height)
+	visible := lines[start:end]
+	if end < len(lines) && len(visible) > 0 {
+		visible[len(visible)-1] = m.styles.EmptyDir.Render(fmt.Sprintf("  … %d more (j/k to scroll, esc to close)", len(lines)-end+1))
+	}
+
+	return m.styles.FileList.
+		Width(m.width).
+		Height(height).
+		Render(strings.Join(visible, "\n"))
+}
+
+// whichKeyLines lists the ways to complete the pending key sequence
+func (m Model) whichKeyLines() []string {
+	var lines []string
+	for _, kb := range m.activeBindings().list {
+		if len(kb.keys) <= len(m.pending) || !kb.keys.hasPrefix(m.pending) {
+			continue
+		}
+		rest := kb.keys[len(m.pending):]
+		lines = append(lines, fmt.Sprintf(" %-8s %s", rest, describeBinding(kb)))
+	}
+	return lines
+}
+
+// overlayWhichKey draws the continuations of the pending key sequence
+// over the bottom of the main content
+func (m Model) overlayWhichKey(content string) string {
+	hints := m.whichKeyLines()
+	if len(hints) == 0 {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	title := fmt.Sprintf(" %s…", m.pending)
+	popup := append([]string{title}, hints...)
+	if len(popup) > len(lines) {
+		popup = popup[:len(lines)]
+	}
+
+	style := m.styles.StatusBar.Width(m.width)
+	for i, hint := range popup {
+		line := style.Render(hint)
+		if i == 0 {
+			line = style.Bold(true).Render(hint)
+		}
+		lines[len(lines)-len(popup)+i] = lipgloss.NewStyle().MaxWidth(m.width).Render(line)
+	}
+	return strings.Join(lines, "\n")
+}
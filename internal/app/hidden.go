@@ -0,0 +1,64 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/fs"
+)
+
+// isHidden reports whether file is left out of listings: dotfiles, names
+// matching the hide patterns and, when enabled, what ignore files ignore
+func (m Model) isHidden(file fs.FileInfo) bool {
+	if m.showHidden {
+		return false
+	}
+	if strings.HasPrefix(file.Name, ".") {
+		return true
+	}
+	for _, pattern := range m.hidePatterns {
+		if ok, _ := filepath.Match(pattern, file.Name); ok {
+			return true
+		}
+	}
+	return m.gitignore && m.ignores.Ignored(file.Path, file.IsDir)
+}
+
+// visibleFiles leaves the hidden entries out of files
+func (m Model) visibleFiles(files []fs.FileInfo) []fs.FileInfo {
+	if m.showHidden {
+		return files
+	}
+	visible := make([]fs.FileInfo, 0, len(files))
+	for _, file := range files {
+		if !m.isHidden(file) {
+			visible = append(visible, file)
+		}
+	}
+	return visible
+}
+
+// setShowHidden shows or hides the hidden entries
+func (m Model) setShowHidden(show bool) (Model, tea.Cmd) {
+	m.showHidden = show
+	m.statusMsg = "Hidden files " + shownHidden(show)
+	m = m.applyFilter()
+	return m, tea.Batch(m.refresh(), m.previewCursor())
+}
+
+// setGitIgnore turns hiding what ignore files ignore on or off
+func (m Model) setGitIgnore(enabled bool) (Model, tea.Cmd) {
+	m.gitignore = enabled
+	m.statusMsg = "Ignore files " + onOff(enabled)
+	m = m.applyFilter()
+	return m, tea.Batch(m.refresh(), m.previewCursor())
+}
+
+// shownHidden describes whether hidden entries are shown
+func shownHidden(show bool) string {
+	if show {
+		return "shown"
+	}
+	return "hidden"
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/utils"
+)
+
+// historySize bounds how many directories each tab remembers
+const historySize = 100
+
+// visit records path in the tab's history unless it is the entry being
+// shown, which is the case when moving back and forward. Visiting a new
+// directory drops the entries ahead of the current one, like a browser.
+func (m Model) visit(path string) Model {
+	if len(m.history) > 0 && m.history[m.historyPos] == path {
+		return m
+	}
+
+	history := append([]string{}, m.history[:min(m.historyPos+1, len(m.history))]...)
+	history = append(history, path)
+	if len(history) > historySize {
+		history = history[len(history)-historySize:]
+	}
+	m.history = history
+	m.historyPos = len(history) - 1
+	return m
+}
+
+// historyJump moves by delta entries through the history
+func (m Model) historyJump(delta int) (Model, tea.Cmd) {
+	pos := m.historyPos + delta
+	if pos < 0 || pos >= len(m.history) {
+		if delta < 0 {
+			m.statusMsg = "Already at the oldest directory"
+		} else {
+			m.statusMsg = "Already at the newest directory"
+		}
+		return m, nil
+	}
+	return m.historyGoto(pos)
+}
+
+// historyGoto shows the directory at position pos of the history
+func (m Model) historyGoto(pos int) (Model, tea.Cmd) {
+	m.historyPos = pos
+	return m.enterDir(m.history[pos], "")
+}
+
+// jumpList shows the history in a menu, the current directory selected
+func (m Model) jumpList() (Model, tea.Cmd) {
+	if len(m.history) == 0 {
+		return m, nil
+	}
+
+	items := make([]string, len(m.history))
+	for i, path := range m.history {
+		items[i] = utils.ShortenPath(path)
+	}
+	m, cmd := m.openMenu("Jump list", items, func(m Model, choice int) (Model, tea.Cmd) {
+		return m.historyGoto(choice)
+	})
+	m.menu.cursor = m.historyPos
+	return m, cmd
+}
@@ -0,0 +1,346 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/fs"
+)
+
+// jobKind identifies the file operation performed by a job
+type jobKind int
+
+const (
+	jobCopy jobKind = iota
+	jobMove
+	jobDelete
+	jobTrash
+	jobRestore
+	jobPurge
+)
+
+// String returns the progress label for the job kind
+func (k jobKind) String() string {
+	switch k {
+	case jobCopy:
+		return "Copying"
+	case jobMove:
+		return "Moving"
+	case jobDelete:
+		return "Deleting"
+	case jobTrash:
+		return "Trashing"
+	case jobRestore:
+		return "Restoring"
+	case jobPurge:
+		return "Purging"
+	}
+	return "Working"
+}
+
+// past returns the completion label for the job kind
+func (k jobKind) past() string {
+	switch k {
+	case jobCopy:
+		return "Copied"
+	case jobMove:
+		return "Moved"
+	case jobDelete:
+		return "Deleted"
+	case jobTrash:
+		return "Trashed"
+	case jobRestore:
+		return "Restored"
+	case jobPurge:
+		return "Purged"
+	}
+	return "Finished"
+}
+
+// job is a file operation waiting in or running from the job queue
+type job struct {
+	id      int
+	kind    jobKind
+	sources []string
+	destDir string
+
+	// Progress, updated from jobProgressMsg
+	items   int
+	files   int
+	current string
+	errs    []error
+
+	events chan tea.Msg
+}
+
+// clipboard holds the paths yanked or cut for a later paste
+type clipboard struct {
+	paths []string
+	cut   bool
+}
+
+// jobProgressMsg is sent while a job processes its files
+type jobProgressMsg struct {
+	id      int
+	items   int
+	files   int
+	current string
+	errs    []error
+}
+
+// jobDoneMsg is sent when a job has processed all of its sources
+type jobDoneMsg struct {
+	id    int
+	steps []fs.Step // Completed changes, for the journal
+	errs  []error
+}
+
+// progressInterval throttles progress messages so large trees don't flood the UI
+const progressInterval = 100 * time.Millisecond
+
+// targets returns the paths an operation applies to: the selection if there
+// is one, otherwise the file under the cursor
+func (m Model) targets() []string {
+	if len(m.selected) > 0 {
+		return m.MarkedPaths()
+	}
+	if len(m.files) > 0 {
+		return []string{m.files[m.cursor].Path}
+	}
+	return nil
+}
+
+// yank copies the targets to the clipboard
+func (m Model) yank(cut bool) (Model, tea.Cmd) {
+	paths := m.targets()
+	if len(paths) == 0 {
+		return m, nil
+	}
+
+	m.clipboard = clipboard{paths: paths, cut: cut}
+	m = m.clearSelection()
+	if cut {
+		m.statusMsg = fmt.Sprintf("Cut %d item(s)", len(paths))
+	} else {
+		m.statusMsg = fmt.Sprintf("Yanked %d item(s)", len(paths))
+	}
+	return m, nil
+}
+
+// paste queues a copy or move of the clipboard into the current directory
+func (m Model) paste() (Model, tea.Cmd) {
+	if len(m.clipboard.paths) == 0 {
+		m.statusMsg = "Clipboard is empty"
+		return m, nil
+	}
+
+	kind := jobCopy
+	paths := m.clipboard.paths
+	if m.clipboard.cut {
+		// Cut files can only be moved once
+		kind = jobMove
+		m.clipboard = clipboard{}
+	}
+	return m.enqueueJob(kind, paths, m.currentPath)
+}
+
+// trashTargets queues moving the targets to the trash
+func (m Model) trashTargets() (Model, tea.Cmd) {
+	paths := m.targets()
+	if len(paths) == 0 {
+		return m, nil
+	}
+
+	m = m.clearSelection()
+	return m.enqueueJob(jobTrash, paths, "")
+}
+
+// confirmDelete asks before queueing a delete of the targets
+func (m Model) confirmDelete() (Model, tea.Cmd) {
+	paths := m.targets()
+	if len(paths) == 0 {
+		return m, nil
+	}
+
+	prompt := fmt.Sprintf("Delete %s permanently?", describePaths(paths))
+	return m.askConfirm(prompt, func(m Model) (Model, tea.Cmd) {
+		m = m.clearSelection()
+		return m.enqueueJob(jobDelete, paths, "")
+	})
+}
+
+// enqueueJob adds a job to the queue, starting it if nothing else is running
+func (m Model) enqueueJob(kind jobKind, sources []string, destDir string) (Model, tea.Cmd) {
+	m.nextJobID++
+	j := &job{
+		id:      m.nextJobID,
+		kind:    kind,
+		sources: sources,
+		destDir: destDir,
+	}
+	m.jobs = append(m.jobs, j)
+
+	if len(m.jobs) == 1 {
+		return m, startJob(j)
+	}
+	m.statusMsg = fmt.Sprintf("%s queued (%d pending)", kind, len(m.jobs)-1)
+	return m, nil
+}
+
+// activeJob returns the running job, if any
+func (m Model) activeJob() *job {
+	if len(m.jobs) == 0 {
+		return nil
+	}
+	return m.jobs[0]
+}
+
+// handleJobProgress records progress of the running job
+func (m Model) handleJobProgress(msg jobProgressMsg) (Model, tea.Cmd) {
+	j := m.activeJob()
+	if j == nil || j.id != msg.id {
+		return m, nil
+	}
+
+	j.items = msg.items
+	j.files = msg.files
+	j.current = msg.current
+	j.errs = msg.errs
+	return m, waitForJob(j.events)
+}
+
+// handleJobDone reports the result of a job, refreshes the listing and
+// starts the next queued job
+func (m Model) handleJobDone(msg jobDoneMsg) (Model, tea.Cmd) {
+	j := m.activeJob()
+	if j == nil || j.id != msg.id {
+		return m, nil
+	}
+
+	m.jobs = m.jobs[1:]
+	m = m.recordJob(j.kind, msg.steps)
+	if len(msg.errs) > 0 {
+		m.statusMsg = ""
+		m.err = fmt.Errorf("%s finished with %d error(s): %v", j.kind, len(msg.errs), msg.errs[0])
+	} else {
+		m.err = nil
+		m.statusMsg = fmt.Sprintf("%s %s", j.kind.past(), describePaths(j.sources))
+	}
+
+	if m.quitAfterJobs && m.activeJob() == nil {
+		if len(msg.errs) == 0 {
+			return m.quit()
+		}
+		m.quitAfterJobs = false // Stay to show what went wrong
+	}
+
+	cmds := []tea.Cmd{m.refresh()}
+	if next := m.activeJob(); next != nil {
+		cmds = append(cmds, startJob(next))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// startJob runs a job in the background and waits for its first message
+func startJob(j *job) tea.Cmd {
+	j.events = make(chan tea.Msg, 16)
+	events := j.events
+	kind, sources, destDir, id := j.kind, j.sources, j.destDir, j.id
+
+	return func() tea.Msg {
+		go runJob(id, kind, sources, destDir, events)
+		return <-events
+	}
+}
+
+// waitForJob waits for the next message from a running job
+func waitForJob(events chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-events
+	}
+}
+
+// runJob performs the file operation, reporting progress on events
+func runJob(id int, kind jobKind, sources []string, destDir string, events chan<- tea.Msg) {
+	defer close(events)
+
+	var errs []error
+	var steps []fs.Step
+	items, files := 0, 0
+	lastReport := time.Time{}
+
+	report := func(current string, force bool) {
+		if !force && time.Since(lastReport) < progressInterval {
+			return
+		}
+		lastReport = time.Now()
+		events <- jobProgressMsg{
+			id:      id,
+			items:   items,
+			files:   files,
+			current: current,
+			errs:    append([]error(nil), errs...),
+		}
+	}
+
+	onFile := func(path string, err error) {
+		files++
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+		report(path, err != nil)
+	}
+
+	for _, src := range sources {
+		name := filepath.Base(src)
+
+		var err error
+		switch kind {
+		case jobCopy:
+			dst := fs.UniquePath(destDir, name)
+			if err = fs.Copy(src, dst, onFile); err == nil {
+				steps = append(steps, fs.NewStep(src, dst))
+			}
+		case jobMove:
+			if filepath.Dir(src) == destDir {
+				break // Already there
+			}
+			dst := fs.UniquePath(destDir, name)
+			if err = fs.Move(src, dst, onFile); err == nil {
+				steps = append(steps, fs.NewStep(src, dst))
+			}
+		case jobDelete:
+			err = fs.Delete(src, onFile)
+		case jobTrash:
+			var trashed string
+			if trashed, err = fs.Trash(src); err == nil {
+				steps = append(steps, fs.NewStep(src, trashed))
+				onFile(src, nil)
+			}
+		case jobRestore:
+			err = fs.RestoreTrashed(src, onFile)
+		case jobPurge:
+			err = fs.PurgeTrashed(src, onFile)
+		}
+
+		items++
+		// The entries that failed were reported one by one already
+		if err != nil && !errors.Is(err, fs.ErrIncomplete) {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		report(src, err != nil)
+	}
+
+	events <- jobDoneMsg{id: id, steps: steps, errs: errs}
+}
+
+// describePaths returns a short description of a list of paths
+func describePaths(paths []string) string {
+	if len(paths) == 1 {
+		return filepath.Base(paths[0])
+	}
+	return fmt.Sprintf("%d items", len(paths))
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/fs"
+	"github.com/icichainz/sushi/internal/utils"
+)
+
+// journalPath is where the undo journal is persisted
+func journalPath() string {
+	return filepath.Join(utils.DataDir(), "journal.json")
+}
+
+// journalMsg is sent when an undo or redo has been attempted
+type journalMsg struct {
+	op   fs.Operation
+	redo bool
+	err  error
+}
+
+// journalKinds maps job kinds to the operations recorded for them;
+// permanent deletes and trash maintenance cannot be undone
+var journalKinds = map[jobKind]fs.OpKind{
+	jobCopy:  fs.OpCopy,
+	jobMove:  fs.OpMove,
+	jobTrash: fs.OpTrash,
+}
+
+// recordJob adds the completed steps of a job to the journal
+func (m Model) recordJob(kind jobKind, steps []fs.Step) Model {
+	opKind, ok := journalKinds[kind]
+	if !ok || len(steps) == 0 {
+		return m
+	}
+	return m.record(opKind, steps)
+}
+
+// record adds an operation to the journal
+func (m Model) record(kind fs.OpKind, steps []fs.Step) Model {
+	if err := m.journal.Record(fs.Operation{Kind: kind, Steps: steps}); err != nil {
+		m.err = err
+	}
+	return m
+}
+
+// undo reverts the last journaled operation in the background
+func (m Model) undo() (Model, tea.Cmd) {
+	return m.replayJournal(false)
+}
+
+// redo re-applies the last undone operation in the background
+func (m Model) redo() (Model, tea.Cmd) {
+	return m.replayJournal(true)
+}
+
+// replayJournal runs an undo or redo once no job is touching the filesystem
+func (m Model) replayJournal(redo bool) (Model, tea.Cmd) {
+	if len(m.jobs) > 0 {
+		m.statusMsg = "Wait for running jobs to finish first"
+		return m, nil
+	}
+
+	journal := m.journal
+	return m, func() tea.Msg {
+		var op fs.Operation
+		var err error
+		if redo {
+			op, err = journal.Redo()
+		} else {
+			op, err = journal.Undo()
+		}
+		return journalMsg{op: op, redo: redo, err: err}
+	}
+}
+
+// handleJournal reports the result of an undo or redo
+func (m Model) handleJournal(msg journalMsg) (Model, tea.Cmd) {
+	switch {
+	case errors.Is(msg.err, fs.ErrNothingToUndo), errors.Is(msg.err, fs.ErrNothingToRedo):
+		m.statusMsg = capitalize(msg.err.Error())
+		return m, nil
+	case msg.err != nil:
+		m.statusMsg = capitalize(msg.err.Error())
+	case msg.redo:
+		m.statusMsg = "Redid " + msg.op.String()
+	default:
+		m.statusMsg = "Undid " + msg.op.String()
+	}
+	return m, m.refresh()
+}
+
+// capitalize upper-cases the first letter of an ASCII message
+func capitalize(s string) string {
+	if s == "" || s[0] < 'a' || s[0] > 'z' {
+		return s
+	}
+	return string(s[0]-'a'+'A') + s[1:]
+}
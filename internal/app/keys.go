@@ -0,0 +1,297 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// keySequence is a list of keys as named by tea.KeyMsg.String, such as
+// ["g", "g"] or ["ctrl+x", "ctrl+s"]
+type keySequence []string
+
+// charKey stands for any letter or digit at the end of a sequence, which
+// is passed to the command as its last argument, as in "m<char>"
+const charKey = "<char>"
+
+// namedKeys are the multi-letter key names understood in key sequences
+var namedKeys = map[string]bool{
+	"up": true, "down": true, "left": true, "right": true, "enter": true,
+	"esc": true, "tab": true, "backspace": true, "delete": true, "insert": true,
+	"home": true, "end": true, "pgup": true, "pgdown": true, "space": true,
+}
+
+// parseKeySequence parses keys written like "gg", "G", "ctrl+r", "m<char>"
+// or "g ctrl+h". Words that are key names (containing + or listed in
+// namedKeys) are one key, anything else is one key per character.
+func parseKeySequence(s string) (keySequence, error) {
+	if s == " " {
+		return keySequence{" "}, nil
+	}
+
+	var seq keySequence
+	for _, word := range strings.Fields(s) {
+		switch {
+		case word == "space":
+			seq = append(seq, " ")
+		case namedKeys[word], len(word) > 1 && strings.Contains(word, "+"), isFunctionKey(word):
+			seq = append(seq, word)
+		default:
+			stem, char := strings.CutSuffix(word, charKey)
+			for _, r := range stem {
+				seq = append(seq, string(r))
+			}
+			if char {
+				seq = append(seq, charKey)
+			}
+		}
+	}
+	if len(seq) == 0 {
+		return nil, fmt.Errorf("empty key sequence")
+	}
+	for i, k := range seq {
+		if k == charKey && (i == 0 || i < len(seq)-1) {
+			return nil, fmt.Errorf("%s must end a sequence of at least two keys", charKey)
+		}
+	}
+	return seq, nil
+}
+
+// isCharKey reports whether k is a key that charKey stands for
+func isCharKey(k string) bool {
+	r := []rune(k)
+	return len(r) == 1 && (unicode.IsLetter(r[0]) || unicode.IsDigit(r[0]))
+}
+
+// keyMatches reports whether key a of one sequence matches key b of another
+func keyMatches(a, b string) bool {
+	return a == b || a == charKey && isCharKey(b) || b == charKey && isCharKey(a)
+}
+
+// isFunctionKey matches f1 to f20
+func isFunctionKey(word string) bool {
+	var n int
+	_, err := fmt.Sscanf(word, "f%d", &n)
+	return err == nil && fmt.Sprintf("f%d", n) == word && n >= 1 && n <= 20
+}
+
+// String writes the sequence the way it is typed in the config file
+func (s keySequence) String() string {
+	words := make([]string, len(s))
+	compact := true
+	for i, k := range s {
+		words[i] = k
+		if k == " " {
+			words[i] = "space"
+		}
+		if len([]rune(words[i])) > 1 && k != charKey {
+			compact = false
+		}
+	}
+	if compact {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words, " ")
+}
+
+// id is the map key of a sequence
+func (s keySequence) id() string {
+	return strings.Join(s, "\x00")
+}
+
+// hasPrefix reports whether prefix is a proper or full prefix of s
+func (s keySequence) hasPrefix(prefix keySequence) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+	for i := range prefix {
+		if !keyMatches(s[i], prefix[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// keyBinding binds a key sequence to a command line
+type keyBinding struct {
+	keys    keySequence
+	command string
+	help    string // Description of a default binding
+}
+
+// keyBindings dispatches key sequences typed in normal and visual mode,
+// and in the list screens for the commands they understand
+type keyBindings struct {
+	list     []keyBinding
+	commands map[string]string // Command line by sequence id
+	prefixes map[string]bool   // Ids of sequences that are the start of longer ones
+}
+
+// lookup finds the command bound to seq; prefix is set when seq is the
+// start of one or more longer sequences. A sequence ending in the key of
+// a charKey binding runs its command with that key as an argument.
+func (b keyBindings) lookup(seq keySequence) (command string, ok, prefix bool) {
+	if command, ok = b.commands[seq.id()]; ok || len(seq) < 2 {
+		return command, ok, b.prefixes[seq.id()]
+	}
+
+	last := seq[len(seq)-1]
+	wild := append(seq[:len(seq)-1:len(seq)-1], charKey)
+	if command, ok = b.commands[wild.id()]; ok && isCharKey(last) {
+		return command + " " + last, true, false
+	}
+	return "", false, b.prefixes[seq.id()]
+}
+
+// newKeyBindings builds the bindings from the defaults and the user's
+// overrides, which map key sequences to command lines. An override
+// replaces any default that it would clash with; an empty command only
+// removes them. Clashes between overrides are reported as errors.
+func newKeyBindings(defaults []KeyCommand, overrides map[string]string) (keyBindings, error) {
+	var problems []string
+	var user []keyBinding
+	var removed []keySequence
+
+	keys := make([]string, 0, len(overrides))
+	for k := range overrides {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		seq, err := parseKeySequence(k)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("keys.%q: %v", k, err))
+			continue
+		}
+		line := strings.TrimPrefix(strings.TrimSpace(overrides[k]), ":")
+		if line == "" {
+			removed = append(removed, seq)
+			continue
+		}
+		if err := checkCommandLine(line); err != nil {
+			problems = append(problems, fmt.Sprintf("keys.%q: %v", k, err))
+			continue
+		}
+		user = append(user, keyBinding{keys: seq, command: line})
+		removed = append(removed, seq)
+	}
+
+	bindings := keyBindings{
+		commands: make(map[string]string),
+		prefixes: make(map[string]bool),
+	}
+	for _, kc := range defaults {
+		for _, k := range kc.Binding.Keys() {
+			seq, err := parseKeySequence(k)
+			if err != nil || clashesWithAny(seq, removed) {
+				continue
+			}
+			if _, ok := bindings.commands[seq.id()]; ok {
+				continue // Earlier bindings take precedence, as in Commands
+			}
+			kb := keyBinding{keys: seq, command: kc.Command, help: kc.Binding.Help().Desc}
+			problems = append(problems, bindings.add(kb)...)
+		}
+	}
+	for _, kb := range user {
+		problems = append(problems, bindings.add(kb)...)
+	}
+
+	if len(problems) > 0 {
+		return bindings, fmt.Errorf("%s", strings.Join(problems, "\n  "))
+	}
+	return bindings, nil
+}
+
+// modeCommands lists the commands understood by the list screens, which
+// take the keys bound to them in normal mode. Text prompts keep Esc and
+// Enter.
+var modeCommands = map[Mode][]string{
+	ModeTrash:     {"quit", "up", "down", "top", "bottom", "toggle-select", "trash", "delete", "cancel", "trash-browser", "parent", "back"},
+	ModeBookmarks: {"quit", "up", "down", "top", "bottom", "open", "pick", "cancel", "parent", "back"},
+	ModeMenu:      {"quit", "up", "down", "top", "bottom", "open", "pick", "cancel", "parent"},
+}
+
+// only returns the bindings running one of commands
+func (b keyBindings) only(commands []string) keyBindings {
+	out := keyBindings{
+		commands: make(map[string]string),
+		prefixes: make(map[string]bool),
+	}
+	for _, kb := range b.list {
+		if name, _ := commandName(kb.command); slices.Contains(commands, name) {
+			out.add(kb)
+		}
+	}
+	return out
+}
+
+// activeBindings returns the bindings for the current mode
+func (m Model) activeBindings() keyBindings {
+	if commands, ok := modeCommands[m.mode]; ok {
+		return m.bindings.only(commands)
+	}
+	return m.bindings
+}
+
+// commandName returns the name of the command run by a command line,
+// aliases resolved, and its arguments
+func commandName(line string) (string, []string) {
+	args, err := splitArgs(line)
+	if err != nil || len(args) == 0 {
+		return "", nil
+	}
+	if cmd, ok := lookupCommand(args[0]); ok {
+		return cmd.name, args[1:]
+	}
+	return args[0], args[1:]
+}
+
+// add registers a binding, reporting clashes with those already added
+func (b *keyBindings) add(kb keyBinding) []string {
+	var problems []string
+	for _, other := range b.list {
+		if kb.keys.hasPrefix(other.keys) || other.keys.hasPrefix(kb.keys) {
+			problems = append(problems, fmt.Sprintf("keys.%q (%s) conflicts with %q (%s)",
+				kb.keys, kb.command, other.keys, other.command))
+		}
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+
+	b.list = append(b.list, kb)
+	b.commands[kb.keys.id()] = kb.command
+	for i := 1; i < len(kb.keys); i++ {
+		b.prefixes[kb.keys[:i].id()] = true
+	}
+	return nil
+}
+
+// clashesWithAny reports whether seq equals, starts or extends any of others
+func clashesWithAny(seq keySequence, others []keySequence) bool {
+	for _, other := range others {
+		if seq.hasPrefix(other) || other.hasPrefix(seq) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCommandLine verifies that line names a known command
+func checkCommandLine(line string) error {
+	args, err := splitArgs(line)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("empty command")
+	}
+	if _, ok := lookupCommand(args[0]); !ok {
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/bookmarks"
+)
+
+func TestParseKeySequence(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    keySequence
+		wantErr bool
+	}{
+		{in: "gg", want: keySequence{"g", "g"}},
+		{in: "G", want: keySequence{"G"}},
+		{in: "ctrl+x ctrl+s", want: keySequence{"ctrl+x", "ctrl+s"}},
+		{in: "g space", want: keySequence{"g", " "}},
+		{in: " ", want: keySequence{" "}},
+		{in: "m<char>", want: keySequence{"m", charKey}},
+		{in: "g enter", want: keySequence{"g", "enter"}},
+		{in: "f5", want: keySequence{"f5"}},
+		{in: "f21", want: keySequence{"f", "2", "1"}},
+		{in: "+", want: keySequence{"+"}},
+		{in: "", wantErr: true},
+		{in: "<char>", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseKeySequence(tt.in)
+		if (err != nil) != tt.wantErr || !slices.Equal(got, tt.want) {
+			t.Errorf("parseKeySequence(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+		if err == nil {
+			if again, _ := parseKeySequence(got.String()); !slices.Equal(again, got) {
+				t.Errorf("%q written as %q reads back as %q", tt.in, got.String(), again)
+			}
+		}
+	}
+}
+
+func TestKeyBindingsLookup(t *testing.T) {
+	binding := func(command string, keys ...string) KeyCommand {
+		return KeyCommand{Binding: key.NewBinding(key.WithKeys(keys...)), Command: command}
+	}
+	defaults := []KeyCommand{
+		binding("quit", "q"),
+		binding("trash", "d"),
+		binding("jump-mark", "'<char>"),
+		binding("sort size", "ctrl+x ctrl+s"),
+		binding("refresh", "q"), // Shadowed by the earlier binding
+	}
+	b, err := newKeyBindings(defaults, map[string]string{
+		"dd": ":yank", // Replaces d
+		"gd": "cd /tmp",
+		"q":  "", // Unbinds q
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		keys    keySequence
+		command string
+		ok      bool
+		prefix  bool
+	}{
+		{keySequence{"d"}, "", false, true},
+		{keySequence{"d", "d"}, "yank", true, false},
+		{keySequence{"g"}, "", false, true},
+		{keySequence{"g", "d"}, "cd /tmp", true, false},
+		{keySequence{"q"}, "", false, false},
+		{keySequence{"'"}, "", false, true},
+		{keySequence{"'", "a"}, "jump-mark a", true, false},
+		{keySequence{"'", "7"}, "jump-mark 7", true, false},
+		{keySequence{"'", "enter"}, "", false, false},
+		{keySequence{"ctrl+x"}, "", false, true},
+		{keySequence{"ctrl+x", "ctrl+s"}, "sort size", true, false},
+		{keySequence{"x"}, "", false, false},
+	}
+	for _, tt := range tests {
+		command, ok, prefix := b.lookup(tt.keys)
+		if command != tt.command || ok != tt.ok || prefix != tt.prefix {
+			t.Errorf("lookup(%q) = %q, %v, %v, want %q, %v, %v",
+				tt.keys, command, ok, prefix, tt.command, tt.ok, tt.prefix)
+		}
+	}
+}
+
+func TestKeyBindingsProblems(t *testing.T) {
+	for _, overrides := range []map[string]string{
+		{"g": "quit", "gx": "trash"},           // g shadows gx
+		{"z": "no-such-command"},               // Unknown command
+		{"<char>": "jump-mark"},                // Invalid sequence
+		{"b<char>": "jump-mark", "ba": "quit"}, // ba shadowed by b<char>
+	} {
+		if _, err := newKeyBindings(nil, overrides); err == nil {
+			t.Errorf("%v accepted", overrides)
+		}
+	}
+}
+
+// keyMsg returns the key press named like in key sequences
+func keyMsg(k string) tea.KeyMsg {
+	for t, name := range map[tea.KeyType]string{tea.KeyEsc: "esc", tea.KeyEnter: "enter", tea.KeyUp: "up", tea.KeyDown: "down"} {
+		if k == name {
+			return tea.KeyMsg{Type: t}
+		}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+func TestResolveKey(t *testing.T) {
+	b, err := newKeyBindings(DefaultKeyMap().Commands(), map[string]string{
+		"J":  "down 2",
+		"dd": "trash",
+		"x":  "q", // Alias of quit
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		mode Mode
+		keys []string
+		want []string // Command resolved after each key
+	}{
+		{ModeNormal, []string{"g", "g"}, []string{"", "top"}},
+		// A key breaking off a sequence counts on its own
+		{ModeNormal, []string{"g", "k"}, []string{"", "up"}},
+		{ModeNormal, []string{"d", "g", "g"}, []string{"", "", "top"}},
+		{ModeNormal, []string{"g", "!"}, []string{"", ""}},
+		// List screens take the remapped keys of the commands they know
+		{ModeTrash, []string{"J", "d", "d", "x"}, []string{"down 2", "", "trash", "q"}},
+		{ModeBookmarks, []string{"J", "enter", "d"}, []string{"down 2", "open", ""}},
+		{ModeBookmarks, []string{"g", "a"}, []string{"", ""}},
+		{ModeMenu, []string{"esc", "G", "y"}, []string{"cancel", "bottom", ""}},
+	}
+	for _, tt := range tests {
+		m := Model{mode: tt.mode, bindings: b}
+		for i, k := range tt.keys {
+			var command string
+			m, command = m.resolveKey(keyMsg(k))
+			if command != tt.want[i] {
+				t.Errorf("mode %d, %q: %q resolved to %q, want %q", tt.mode, tt.keys, k, command, tt.want[i])
+			}
+		}
+		if len(m.pending) > 0 {
+			t.Errorf("mode %d, %q: left %q pending", tt.mode, tt.keys, m.pending)
+		}
+	}
+}
+
+func TestListScreenKeys(t *testing.T) {
+	b, err := newKeyBindings(DefaultKeyMap().Commands(), map[string]string{"n": "down", "e": "cancel"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := Model{bindings: b, keys: DefaultKeyMap(), bookmarks: &bookmarks.Bookmarks{}}
+	m, _ = m.openMenu("Pick", []string{"a", "b", "c"}, func(m Model, choice int) (Model, tea.Cmd) {
+		m.statusMsg = fmt.Sprint("picked ", choice)
+		return m, nil
+	})
+
+	for _, k := range []string{"n", "n", "k"} {
+		mm, _ := m.handleMenuKey(keyMsg(k))
+		m = mm.(Model)
+	}
+	mm, _ := m.handleMenuKey(keyMsg("enter"))
+	if m = mm.(Model); m.mode != ModeNormal || m.statusMsg != "picked 1" {
+		t.Fatalf("menu closed in mode %d with %q, want picked 1", m.mode, m.statusMsg)
+	}
+
+	m, _ = m.openMenu("Pick", []string{"a"}, nil)
+	mm, _ = m.handleMenuKey(keyMsg("e"))
+	if m = mm.(Model); m.mode != ModeNormal {
+		t.Fatalf("remapped cancel left the menu open in mode %d", m.mode)
+	}
+}
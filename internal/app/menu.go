@@ -0,0 +1,104 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// menuState is a list of choices shown in place of the files, active in
+// ModeMenu
+type menuState struct {
+	title      string
+	items      []string
+	cursor     int
+	onSelect   func(Model, int) (Model, tea.Cmd)
+	returnMode Mode // Mode to go back to once closed
+}
+
+// openMenu switches to ModeMenu with the given choices
+func (m Model) openMenu(title string, items []string, onSelect func(Model, int) (Model, tea.Cmd)) (Model, tea.Cmd) {
+	m.menu = menuState{title: title, items: items, onSelect: onSelect, returnMode: m.mode}
+	m.mode = ModeMenu
+	return m, nil
+}
+
+// handleMenuKey moves through the menu; enter or a digit picks an item
+func (m Model) handleMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	menu := m.menu
+
+	m, command := m.resolveKey(msg)
+	name, args := commandName(command)
+	switch name {
+	case "cancel", "quit", "parent":
+		m.menu = menuState{}
+		m.mode = menu.returnMode
+		return m, nil
+
+	case "up", "down", "top", "bottom":
+		m.menu.cursor = moveListCursor(menu.cursor, len(menu.items), name, args)
+		return m, nil
+
+	case "open", "pick":
+		return m.pickMenu(menu.cursor)
+	}
+
+	if s := msg.String(); command == "" && len(s) == 1 && s[0] >= '1' && s[0] <= '9' {
+		if choice := int(s[0] - '1'); choice < len(menu.items) {
+			return m.pickMenu(choice)
+		}
+	}
+	return m, nil
+}
+
+// moveListCursor returns the cursor of a list of n entries moved by one of
+// the up, down, top and bottom commands
+func moveListCursor(cursor, n int, command string, args []string) int {
+	switch command {
+	case "up":
+		cursor -= countArg(args)
+	case "down":
+		cursor += countArg(args)
+	case "top":
+		cursor = 0
+	case "bottom":
+		cursor = n - 1
+	}
+	return max(0, min(n-1, cursor))
+}
+
+// pickMenu closes the menu and runs its action for the chosen item
+func (m Model) pickMenu(choice int) (Model, tea.Cmd) {
+	menu := m.menu
+	m.menu = menuState{}
+	m.mode = menu.returnMode
+	return menu.onSelect(m, choice)
+}
+
+// renderMenu renders the menu title and its numbered items
+func (m Model) renderMenu() string {
+	height := m.height - 4
+	lines := []string{m.styles.Header.Render(" " + m.menu.title), ""}
+
+	rows := max(1, height-len(lines))
+	start := max(0, m.menu.cursor-rows+1)
+	end := min(len(m.menu.items), start+rows)
+	for i := start; i < end; i++ {
+		label := fmt.Sprintf("  %s", m.menu.items[i])
+		if i < 9 {
+			label = fmt.Sprintf("%d %s", i+1, m.menu.items[i])
+		}
+		line := fmt.Sprintf("%-*s", max(0, m.width-4), label)
+		if i == m.menu.cursor {
+			lines = append(lines, m.styles.SelectedFile.Render(line))
+		} else {
+			lines = append(lines, m.styles.File.Render(line))
+		}
+	}
+
+	return m.styles.FileList.
+		Width(m.width).
+		Height(height).
+		Render(strings.Join(lines, "\n"))
+}
@@ -1,20 +1,72 @@
 package app
 
 import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/bookmarks"
+	"github.com/icichainz/sushi/internal/config"
+	"github.com/icichainz/sushi/internal/frecency"
 	"github.com/icichainz/sushi/internal/fs"
+	"github.com/icichainz/sushi/internal/ignore"
 	"github.com/icichainz/sushi/internal/ui"
 	"github.com/icichainz/sushi/internal/ui/components"
+	"github.com/icichainz/sushi/internal/watch"
 )
 
 // Model represents the application state
 type Model struct {
 	// Current state
 	currentPath string
-	files       []fs.FileInfo
+	allFiles    []fs.FileInfo // Full listing of currentPath
+	files       []fs.FileInfo // Listing shown, narrowed by filter
 	cursor      int
-	selected    map[string]bool
+	selected    map[string]fs.FileInfo // Marked entries by path, across directories
+	visual      visualState
+
+	// Tabs, the active one's state lives in the fields above and below
+	tabs      []tabState
+	activeTab int
+	tabID     int // Identifies the active tab in asynchronous messages
+	nextTabID int
+	layout    layoutKind
+	otherTab  int            // Unfocused pane's tab in the dual layout
+	listings  *listingCache  // Recently scanned directories, for all tabs
+	watcher   *watch.Watcher // Reports changes to what is on screen, nil if unsupported
+	watched   []string       // Paths last given to the watcher
+	treeView  bool
+	physical  bool // Resolve symlinks when entering directories
+	tree      *treeState
+
+	// Navigation history of the active tab, and the entry under the cursor
+	// when each directory was last left
+	history    []string
+	historyPos int
+	lastCursor map[string]string
+
+	// Entries left out of listings until shown, and how many the current
+	// one has
+	showHidden   bool
+	hidePatterns []string
+	gitignore    bool
+	ignores      *ignore.Matcher
+	hidden       int
+
+	// Sort order of the current directory, of those without their own, and
+	// the orders chosen for each directory
+	order        fs.SortOrder
+	defaultOrder fs.SortOrder
+	sortOrders   *sortMemory
+
+	// Search state, typed in ModeSearch
+	filter      string
+	matches     map[string][]int // Matched rune positions by path
+	searchInput textinput.Model
 
 	// Preview state
 	preview            components.PreviewContent
@@ -22,6 +74,8 @@ type Model struct {
 	previewWidth       int
 	syntaxHighlight    bool
 	syntaxTheme        string
+	previewMaxLines    int
+	previewMaxSize     int64
 
 	// UI state
 	width  int
@@ -29,11 +83,55 @@ type Model struct {
 	styles ui.Styles
 
 	// Key bindings
-	keys KeyMap
+	keys     KeyMap
+	bindings keyBindings
+	pending  keySequence // Keys typed so far of a multi-key sequence
 
 	// Mode
 	mode Mode
 
+	// Confirmation prompt, active in ModeConfirm
+	confirm confirmPrompt
+
+	// Choice list, active in ModeMenu
+	menu menuState
+
+	// Key binding overview, active in ModeHelp
+	help helpState
+
+	// Rules for opening files, most preferred first
+	openers []config.Opener
+
+	// Command prompt, active in ModeCommand
+	cmdline commandLine
+
+	// Recursive finder, active in ModeFinder
+	finder      finderState
+	walkOptions fs.WalkOptions
+
+	// Trash browser state, active in ModeTrash, and the marks set aside
+	// until it is closed
+	trashItems []fs.TrashItem
+	keptMarks  map[string]fs.FileInfo
+
+	// Saved directories, and their manager, active in ModeBookmarks
+	bookmarks *bookmarks.Bookmarks
+	marks     bookmarkManager
+
+	// File operations
+	clipboard     clipboard
+	jobs          []*job // Queue, the first job is running
+	nextJobID     int
+	journal       *fs.Journal
+	quitAfterJobs bool // Quit requested, waiting for the running job
+
+	// Visited directories, ranked for :z
+	frecency *frecency.Database
+
+	// Restrictions when choosing files for another program, and the result
+	picker *PickOptions
+	picked []string
+
 	// Status message
 	statusMsg string
 	err       error
@@ -46,21 +144,69 @@ const (
 	ModeNormal Mode = iota
 	ModeSearch
 	ModeCommand
+	ModeConfirm
+	ModeTrash
+	ModeFinder
+	ModeVisual
+	ModeMenu
+	ModeHelp
+	ModeBookmarks
 )
 
 // KeyMap defines all key bindings
 type KeyMap struct {
 	Up              key.Binding
 	Down            key.Binding
+	Top             key.Binding
+	NewTab          key.Binding
+	CloseTab        key.Binding
+	NextTab         key.Binding
+	SwitchPane      key.Binding
+	CopyToPane      key.Binding
+	MoveToPane      key.Binding
+	PrevTab         key.Binding
+	Bottom          key.Binding
+	Home            key.Binding
 	Left            key.Binding
 	Right           key.Binding
 	Enter           key.Binding
 	Back            key.Binding
+	Forward         key.Binding
+	JumpList        key.Binding
+	Mark            key.Binding
+	JumpToMark      key.Binding
+	Bookmarks       key.Binding
+	FrecencyJump    key.Binding
 	Delete          key.Binding
+	DeleteForever   key.Binding
+	Trash           key.Binding
+	Restore         key.Binding
+	Cancel          key.Binding
+	Search          key.Binding
+	SearchNext      key.Binding
+	SearchPrev      key.Binding
+	Finder          key.Binding
+	Command         key.Binding
+	Undo            key.Binding
+	Redo            key.Binding
+	Select          key.Binding
+	Visual          key.Binding
+	SelectAll       key.Binding
+	InvertSelection key.Binding
+	Yank            key.Binding
+	Cut             key.Binding
+	Paste           key.Binding
+	OpenWith        key.Binding
+	BulkRename      key.Binding
 	Quit            key.Binding
 	Help            key.Binding
 	Preview         key.Binding
 	ToggleSyntax    key.Binding
+	TreeView        key.Binding
+	Expand          key.Binding
+	CollapseAll     key.Binding
+	Sort            key.Binding
+	ToggleHidden    key.Binding
 }
 
 // DefaultKeyMap returns the default key bindings
@@ -74,6 +220,46 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("down", "j"),
 			key.WithHelp("↓/j", "move down"),
 		),
+		Top: key.NewBinding(
+			key.WithKeys("gg"),
+			key.WithHelp("gg", "go to top"),
+		),
+		Bottom: key.NewBinding(
+			key.WithKeys("G"),
+			key.WithHelp("G", "go to bottom"),
+		),
+		Home: key.NewBinding(
+			key.WithKeys("gh"),
+			key.WithHelp("gh", "go home"),
+		),
+		NewTab: key.NewBinding(
+			key.WithKeys("ctrl+t"),
+			key.WithHelp("ctrl+t", "new tab"),
+		),
+		CloseTab: key.NewBinding(
+			key.WithKeys("ctrl+w"),
+			key.WithHelp("ctrl+w", "close tab"),
+		),
+		NextTab: key.NewBinding(
+			key.WithKeys("gt"),
+			key.WithHelp("gt", "next tab"),
+		),
+		SwitchPane: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "other pane / next tab"),
+		),
+		CopyToPane: key.NewBinding(
+			key.WithKeys("f5"),
+			key.WithHelp("f5", "copy to other pane"),
+		),
+		MoveToPane: key.NewBinding(
+			key.WithKeys("f6"),
+			key.WithHelp("f6", "move to other pane"),
+		),
+		PrevTab: key.NewBinding(
+			key.WithKeys("shift+tab", "gT"),
+			key.WithHelp("shift+tab/gT", "previous tab"),
+		),
 		Left: key.NewBinding(
 			key.WithKeys("left", "h"),
 			key.WithHelp("←/h", "parent dir"),
@@ -87,12 +273,116 @@ func DefaultKeyMap() KeyMap {
 			key.WithHelp("enter", "open"),
 		),
 		Back: key.NewBinding(
-			key.WithKeys("backspace"),
-			key.WithHelp("backspace", "back"),
+			key.WithKeys("backspace", "H"),
+			key.WithHelp("backspace/H", "back in history"),
+		),
+		Forward: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "forward in history"),
+		),
+		JumpList: key.NewBinding(
+			key.WithKeys("gj"),
+			key.WithHelp("gj", "jump list"),
+		),
+		Mark: key.NewBinding(
+			key.WithKeys("m<char>"),
+			key.WithHelp("m<char>", "bookmark directory"),
+		),
+		JumpToMark: key.NewBinding(
+			key.WithKeys("'<char>"),
+			key.WithHelp("'<char>", "jump to bookmark"),
+		),
+		Bookmarks: key.NewBinding(
+			key.WithKeys("B"),
+			key.WithHelp("B", "bookmark manager"),
+		),
+		FrecencyJump: key.NewBinding(
+			key.WithKeys("Z"),
+			key.WithHelp("Z", "jump to a frequent directory"),
 		),
 		Delete: key.NewBinding(
 			key.WithKeys("d"),
-			key.WithHelp("d", "delete"),
+			key.WithHelp("d", "move to trash"),
+		),
+		DeleteForever: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "delete permanently"),
+		),
+		Trash: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "trash browser"),
+		),
+		Restore: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "restore from trash"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel / clear"),
+		),
+		Search: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "search"),
+		),
+		SearchNext: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "next match"),
+		),
+		SearchPrev: key.NewBinding(
+			key.WithKeys("N"),
+			key.WithHelp("N", "previous match"),
+		),
+		Finder: key.NewBinding(
+			key.WithKeys("ctrl+p"),
+			key.WithHelp("ctrl+p", "find file"),
+		),
+		Command: key.NewBinding(
+			key.WithKeys(":"),
+			key.WithHelp(":", "command line"),
+		),
+		Undo: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "undo"),
+		),
+		Redo: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "redo"),
+		),
+		Select: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "toggle selection"),
+		),
+		Visual: key.NewBinding(
+			key.WithKeys("V"),
+			key.WithHelp("V", "visual selection"),
+		),
+		SelectAll: key.NewBinding(
+			key.WithKeys("ctrl+a"),
+			key.WithHelp("ctrl+a", "select all"),
+		),
+		InvertSelection: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "invert selection"),
+		),
+		Yank: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "yank (copy)"),
+		),
+		Cut: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "cut"),
+		),
+		Paste: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "paste"),
+		),
+		OpenWith: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "open with…"),
+		),
+		BulkRename: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "bulk rename"),
 		),
 		Quit: key.NewBinding(
 			key.WithKeys("q", "ctrl+c"),
@@ -110,45 +400,217 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("s"),
 			key.WithHelp("s", "toggle syntax"),
 		),
+		TreeView: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "toggle tree view"),
+		),
+		Expand: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "expand/collapse directory"),
+		),
+		CollapseAll: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "collapse all"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "change the sort order"),
+		),
+		ToggleHidden: key.NewBinding(
+			key.WithKeys("zh", "."),
+			key.WithHelp("zh/.", "show/hide hidden files"),
+		),
+	}
+}
+
+// KeyCommand binds a key to a command line, such as "set preview"
+type KeyCommand struct {
+	Binding key.Binding
+	Command string
+}
+
+// Commands returns the command run by each binding, in dispatch order
+func (k KeyMap) Commands() []KeyCommand {
+	return []KeyCommand{
+		{k.Quit, "quit"},
+		{k.Up, "up"},
+		{k.Down, "down"},
+		{k.Top, "top"},
+		{k.Bottom, "bottom"},
+		{k.Home, "cd ~"},
+		{k.Left, "parent"},
+		{k.Back, "back"},
+		{k.Forward, "forward"},
+		{k.JumpList, "jumps"},
+		{k.Mark, "mark"},
+		{k.JumpToMark, "jump-mark"},
+		{k.Bookmarks, "bookmarks"},
+		{k.FrecencyJump, "command-line z"},
+		{k.Right, "open"},
+		{k.Enter, "open"},
+		{k.OpenWith, "open-with"},
+		{k.NewTab, "tab-new"},
+		{k.CloseTab, "tab-close"},
+		{k.NextTab, "tab-next"},
+		{k.SwitchPane, "switch-pane"},
+		{k.CopyToPane, "copy"},
+		{k.MoveToPane, "move"},
+		{k.PrevTab, "tab-prev"},
+		{k.Select, "toggle-select"},
+		{k.Visual, "visual"},
+		{k.SelectAll, "select-all"},
+		{k.InvertSelection, "invert-selection"},
+		{k.Yank, "yank"},
+		{k.Cut, "cut"},
+		{k.Paste, "paste"},
+		{k.BulkRename, "bulk-rename"},
+		{k.Delete, "trash"},
+		{k.DeleteForever, "delete"},
+		{k.Trash, "trash-browser"},
+		{k.Search, "search"},
+		{k.SearchNext, "search-next"},
+		{k.SearchPrev, "search-prev"},
+		{k.Cancel, "cancel"},
+		{k.Finder, "find"},
+		{k.Command, "command-line"},
+		{k.Help, "help"},
+		{k.Undo, "undo"},
+		{k.Redo, "redo"},
+		{k.Preview, "set preview"},
+		{k.ToggleSyntax, "set syntax"},
+		{k.TreeView, "set tree"},
+		{k.Expand, "expand"},
+		{k.CollapseAll, "collapse-all"},
+		{k.Sort, "command-line sort"},
+		{k.ToggleHidden, "set hidden"},
 	}
 }
 
-// NewModel creates a new model with the given starting path
-func NewModel(path string) Model {
+// NewModel creates a new model with one tab per starting path and the
+// given settings. An error is returned if the configured key bindings are
+// invalid.
+func NewModel(paths []string, cfg config.Config) (Model, error) {
+	orders, ordersErr := loadSortMemory(sortOrdersPath())
+	orderFor := func(dir string) fs.SortOrder {
+		if order, ok := orders.get(dir); ok {
+			return order
+		}
+		return cfg.Sort.Order()
+	}
+
+	if cfg.Navigation.Physical {
+		// Start where the symlinks lead, as enterDir would
+		resolved := make([]string, len(paths))
+		for i, path := range paths {
+			resolved[i] = path
+			if real, err := filepath.EvalSymlinks(path); err == nil {
+				resolved[i] = real
+			}
+		}
+		paths = resolved
+	}
+
+	tabs := make([]tabState, len(paths))
+	for i, path := range paths {
+		tabs[i] = newTab(i, path, orderFor(path))
+	}
+
+	path := paths[0]
 	files, err := fs.ScanDirectory(path)
 	if err != nil {
 		files = []fs.FileInfo{}
 	}
+	fs.SortFiles(files, tabs[0].order)
+
+	journal, journalErr := fs.LoadJournal(journalPath())
+	marks, marksErr := bookmarks.Load(bookmarksPath())
+	visited, visitedErr := frecency.Load(frecencyPath())
+	watcher, watchErr := watch.New()
+	if errors.Is(watchErr, watch.ErrUnsupported) {
+		watchErr = nil
+	}
 
 	m := Model{
 		currentPath:     path,
+		allFiles:        files,
 		files:           files,
 		cursor:          0,
-		selected:        make(map[string]bool),
-		styles:          ui.DefaultStyles(),
+		selected:        tabs[0].selected,
+		tabs:            tabs,
+		nextTabID:       len(tabs) - 1,
+		layout:          layoutSingle,
+		listings:        newListingCache(),
+		watcher:         watcher,
+		tree:            newTreeState(),
+		physical:        cfg.Navigation.Physical,
+		lastCursor:      make(map[string]string),
+		styles:          cfg.Styles.Apply(ui.DefaultStyles()),
 		keys:            DefaultKeyMap(),
 		mode:            ModeNormal,
-		previewEnabled:  true,
-		previewWidth:    50, // 50% of screen
-		syntaxHighlight: true,
-		syntaxTheme:     "monokai", // Can be: monokai, dracula, github, nord, etc.
+		searchInput:     newSearchInput(),
+		walkOptions:     cfg.Finder.WalkOptions(),
+		cmdline:         newCommandLine(),
+		openers:         cfg.Openers,
+		order:           tabs[0].order,
+		defaultOrder:    cfg.Sort.Order(),
+		sortOrders:      orders,
+		showHidden:      cfg.Hidden.Show,
+		hidePatterns:    cfg.Hidden.Patterns,
+		gitignore:       cfg.Hidden.GitIgnore,
+		ignores:         ignore.NewMatcher(),
+		previewEnabled:  cfg.Preview.Enabled,
+		previewWidth:    cfg.Preview.ListWidth,
+		syntaxHighlight: cfg.Preview.SyntaxHighlight,
+		syntaxTheme:     cfg.Preview.SyntaxTheme,
+		previewMaxLines: cfg.Preview.MaxLines,
+		previewMaxSize:  cfg.Preview.MaxSize,
+		journal:         journal,
+		bookmarks:       marks,
+		frecency:        visited,
+		err:             errors.Join(journalErr, marksErr, visitedErr, ordersErr, watchErr),
 	}
 
+	m = m.applyFilter()
+
 	// Load initial preview
-	if len(files) > 0 {
-		config := components.PreviewConfig{
-			MaxLines:        100,
-			SyntaxHighlight: m.syntaxHighlight,
-			SyntaxTheme:     m.syntaxTheme,
-			MaxPreviewSize:  10 * 1024 * 1024,
-		}
-		m.preview = components.LoadPreviewWithConfig(files[0], config)
+	if len(m.files) > 0 && m.previewEnabled {
+		m.preview = components.LoadPreviewWithConfig(m.files[0], m.previewConfig())
 	}
+	m = m.watchShown()
 
-	return m
+	m.bindings, err = newKeyBindings(m.keys.Commands(), cfg.Keys)
+	if err != nil {
+		return m, fmt.Errorf("key bindings:\n  %w", err)
+	}
+	return m, nil
+}
+
+// previewConfig returns the settings previews are loaded with
+func (m Model) previewConfig() components.PreviewConfig {
+	return components.PreviewConfig{
+		MaxLines:        m.previewMaxLines,
+		SyntaxHighlight: m.syntaxHighlight,
+		SyntaxTheme:     m.syntaxTheme,
+		MaxPreviewSize:  m.previewMaxSize,
+	}
 }
 
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
-	return nil
+	return waitForChanges(m.watcher)
+}
+
+// CurrentPath returns the directory shown in the active tab
+func (m Model) CurrentPath() string {
+	return m.currentPath
+}
+
+// MarkedPaths returns the files marked in the active tab, sorted
+func (m Model) MarkedPaths() []string {
+	paths := make([]string, 0, len(m.selected))
+	for path := range m.selected {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
 }
\ No newline at end of file
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/config"
+	"github.com/icichainz/sushi/internal/fs"
+)
+
+// openerMatches reports whether a rule applies to a file with the given
+// MIME type
+func openerMatches(o config.Opener, file fs.FileInfo, mimeType string) bool {
+	for _, pattern := range o.Match {
+		switch {
+		case strings.HasPrefix(pattern, "."):
+			if strings.EqualFold(filepath.Ext(file.Name), pattern) {
+				return true
+			}
+		case strings.Contains(pattern, "/"):
+			if ok, _ := path.Match(pattern, mimeType); ok {
+				return true
+			}
+		default:
+			if ok, _ := filepath.Match(pattern, file.Name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// openerCommand builds the process opening path with a rule
+func openerCommand(o config.Opener, path string) (*exec.Cmd, error) {
+	line := os.Expand(o.Command, func(name string) string {
+		if name == "EDITOR" {
+			return editor()
+		}
+		return os.Getenv(name)
+	})
+	args, err := splitArgs(line)
+	if err != nil {
+		return nil, fmt.Errorf("opener %s: %w", o.Name, err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("opener %s: empty command", o.Name)
+	}
+
+	placed := false
+	for i, arg := range args {
+		if strings.Contains(arg, "{}") {
+			args[i] = strings.ReplaceAll(arg, "{}", path)
+			placed = true
+		}
+	}
+	if !placed {
+		args = append(args, path)
+	}
+	return exec.Command(args[0], args[1:]...), nil
+}
+
+// openerAvailable reports whether a rule's program is installed
+func openerAvailable(o config.Opener) bool {
+	cmd, err := openerCommand(o, "")
+	if err != nil {
+		return false
+	}
+	_, err = exec.LookPath(cmd.Args[0])
+	return err == nil
+}
+
+// openersFor lists the installed rules matching file, most preferred first
+func (m Model) openersFor(file fs.FileInfo) []config.Opener {
+	mimeType := fs.MimeType(file.Path)
+	var found []config.Opener
+	for _, opener := range m.openers {
+		if openerMatches(opener, file, mimeType) && openerAvailable(opener) {
+			found = append(found, opener)
+		}
+	}
+	return found
+}
+
+// openedMsg is sent when a terminal program opened from sushi exits
+type openedMsg struct {
+	err error
+}
+
+// openFile opens file with the preferred matching rule
+func (m Model) openFile(file fs.FileInfo) (Model, tea.Cmd) {
+	openers := m.openersFor(file)
+	if len(openers) == 0 {
+		return m.fail(fmt.Errorf("no opener for %s (%s)", file.Name, fs.MimeType(file.Path))), nil
+	}
+	return m.openWith(file, openers[0])
+}
+
+// openWith opens file with a rule, suspending sushi for terminal programs
+func (m Model) openWith(file fs.FileInfo, opener config.Opener) (Model, tea.Cmd) {
+	cmd, err := openerCommand(opener, file.Path)
+	if err != nil {
+		return m.fail(err), nil
+	}
+	cmd.Dir = filepath.Dir(file.Path)
+
+	if opener.Terminal {
+		return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+			return openedMsg{err: err}
+		})
+	}
+
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return m.fail(err), nil
+	}
+	// Reap the process once it exits, sushi does not wait for it
+	go cmd.Wait()
+
+	m.statusMsg = fmt.Sprintf("Opened %s with %s", file.Name, filepath.Base(cmd.Args[0]))
+	return m, nil
+}
+
+// handleOpened reports the exit of a terminal program and reloads the
+// listing, which it may have changed
+func (m Model) handleOpened(msg openedMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m = m.fail(msg.err)
+	}
+	return m, m.loadDirectoryAt(m.currentPath, m.cursorPath())
+}
+
+// openWithMenu lists every rule matching the file under the cursor
+func (m Model) openWithMenu() (Model, tea.Cmd) {
+	if len(m.files) == 0 {
+		return m, nil
+	}
+	file := m.files[m.cursor]
+	if file.IsDir {
+		return m.enterDir(file.Path, "")
+	}
+
+	openers := m.openersFor(file)
+	if len(openers) == 0 {
+		return m.fail(fmt.Errorf("no opener for %s (%s)", file.Name, fs.MimeType(file.Path))), nil
+	}
+
+	items := make([]string, len(openers))
+	for i, opener := range openers {
+		items[i] = fmt.Sprintf("%s (%s)", opener.Name, opener.Command)
+	}
+	return m.openMenu("Open "+file.Name+" with", items, func(m Model, choice int) (Model, tea.Cmd) {
+		return m.openWith(file, openers[choice])
+	})
+}
+
+// editor returns the user's preferred editor command
+func editor() string {
+	if editor := os.Getenv("VISUAL"); editor != "" {
+		return editor
+	}
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+	return "vi"
+}
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// layoutKind is the arrangement of the main area
+type layoutKind string
+
+const (
+	layoutSingle  layoutKind = "single"  // One file list
+	layoutDual    layoutKind = "dual"    // Two tabs side by side, commander style
+	layoutColumns layoutKind = "columns" // Parent, current and preview, ranger style
+)
+
+// layouts lists the layouts in the order :set layout cycles through them
+var layouts = []layoutKind{layoutSingle, layoutDual, layoutColumns}
+
+// parseLayout finds a layout by name, cycling to the next one when empty
+func parseLayout(name string, current layoutKind) (layoutKind, error) {
+	if name == "" {
+		for i, l := range layouts {
+			if l == current {
+				return layouts[(i+1)%len(layouts)], nil
+			}
+		}
+		return layoutSingle, nil
+	}
+	for _, l := range layouts {
+		if string(l) == name {
+			return l, nil
+		}
+	}
+	return "", fmt.Errorf("unknown layout %q", name)
+}
+
+// setLayout switches the layout. The dual layout shows the active tab and
+// a second one, opened in the same directory if there is only one tab.
+func (m Model) setLayout(layout layoutKind) (Model, tea.Cmd) {
+	m.layout = layout
+	m.statusMsg = "Layout " + string(layout)
+	if layout == layoutColumns {
+		return m, tea.Batch(m.loadParentColumn(), m.previewCursor())
+	}
+	if layout != layoutDual {
+		return m, m.previewCursor()
+	}
+
+	if len(m.tabs) == 1 {
+		m = m.saveTab()
+		m.nextTabID++
+		m.tabs = append(m.tabs, newTab(m.nextTabID, m.currentPath, m.order))
+	}
+	if m.otherTab == m.activeTab || m.otherTab >= len(m.tabs) {
+		m.otherTab = (m.activeTab + 1) % len(m.tabs)
+	}
+	return m, tea.Batch(m.loadOtherPane(), m.previewCursor())
+}
+
+// otherPane returns the index of the unfocused pane's tab, or -1 outside
+// the dual layout
+func (m Model) otherPane() int {
+	if m.layout != layoutDual || m.otherTab == m.activeTab || m.otherTab >= len(m.tabs) {
+		return -1
+	}
+	return m.otherTab
+}
+
+// otherPaneModel returns the model as it looks from the unfocused pane
+func (m Model) otherPaneModel() Model {
+	return m.loadTab(m.otherPane())
+}
+
+// loadOtherPane reloads the unfocused pane's directory
+func (m Model) loadOtherPane() tea.Cmd {
+	if m.otherPane() < 0 {
+		return nil
+	}
+	other := m.otherPaneModel()
+	return other.loadDirectoryAt(other.currentPath, other.cursorPath())
+}
+
+// switchPane moves the focus to the other pane, or to the next tab in the
+// single layout
+func (m Model) switchPane() (Model, tea.Cmd) {
+	if other := m.otherPane(); other >= 0 {
+		return m.switchTab(other)
+	}
+	return m.switchTab((m.activeTab + 1) % len(m.tabs))
+}
+
+// applyToOtherPane runs f with the unfocused pane's tab made active
+func (m Model) applyToOtherPane(f func(Model) Model) Model {
+	active := m.activeTab
+	m = m.saveTab().loadTab(m.otherPane())
+	m = f(m)
+	return m.saveTab().loadTab(active)
+}
+
+// transferTo queues copying or moving the targets into dest, which
+// defaults to the other pane's directory
+func (m Model) transferTo(kind jobKind, args []string) (Model, tea.Cmd) {
+	var dest string
+	switch {
+	case len(args) > 0:
+		dest = m.resolvePath(args[0])
+	case m.otherPane() >= 0:
+		dest = m.otherPaneModel().currentPath
+	default:
+		return m.fail(fmt.Errorf("%s needs a destination outside the dual layout", kind)), nil
+	}
+
+	paths := m.targets()
+	if len(paths) == 0 {
+		return m, nil
+	}
+	m = m.clearSelection()
+	return m.enqueueJob(kind, paths, dest)
+}
+
+// renderDualView renders both panes, plus the preview as a third column
+func (m Model) renderDualView() string {
+	previewWidth := 0
+	if m.previewEnabled {
+		previewWidth = m.width / 3
+	}
+	leftWidth := (m.width - previewWidth) / 2
+	rightWidth := m.width - previewWidth - leftWidth
+
+	focused := m.renderPane(leftWidth, true)
+	other := m.otherPaneModel().renderPane(rightWidth, false)
+	left, right := focused, other
+	if m.otherTab < m.activeTab {
+		left, right = other, focused
+	}
+
+	columns := []string{left, right}
+	if previewWidth > 0 {
+		columns = append(columns, m.renderPreview(previewWidth))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+}
+
+// renderPane renders a file list under a line naming its directory
+func (m Model) renderPane(width int, focused bool) string {
+	titleStyle := m.styles.StatusBar
+	if focused {
+		titleStyle = m.styles.Header
+	} else {
+		// Keep the cursor visible without competing with the focused pane
+		m.styles.SelectedFile = m.styles.File.Underline(true)
+	}
+
+	title := " " + m.currentPath
+	if maxLen := width - 2; maxLen > 4 {
+		title = truncateStart(title, maxLen, " …")
+	}
+
+	m.height--
+	list := m.renderFileList(width)
+	return strings.Join([]string{
+		titleStyle.Width(width).MaxWidth(width).Render(title),
+		list,
+	}, "\n")
+}
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/config"
+	"github.com/icichainz/sushi/internal/fs"
+)
+
+// PickOptions restricts what can be picked when choosing files for
+// another program
+type PickOptions struct {
+	Multiple  bool // Allow picking every marked entry, not only one
+	DirsOnly  bool
+	FilesOnly bool
+}
+
+// accepts reports whether file may be picked
+func (o PickOptions) accepts(file fs.FileInfo) bool {
+	switch {
+	case o.DirsOnly:
+		return file.IsDir
+	case o.FilesOnly:
+		return !file.IsDir
+	}
+	return true
+}
+
+// hint describes how to pick, shown until the first message
+func (o PickOptions) hint() string {
+	what := "a file"
+	switch {
+	case o.DirsOnly && o.Multiple:
+		what = "directories"
+	case o.DirsOnly:
+		what = "a directory"
+	case o.Multiple:
+		what = "files"
+	}
+	if o.Multiple {
+		return fmt.Sprintf("Pick %s: space marks · enter picks · q cancels", what)
+	}
+	return fmt.Sprintf("Pick %s: enter picks · q cancels", what)
+}
+
+// NewPicker creates a model choosing files for another program. Enter
+// picks the marked entries or the one under the cursor and quits; the
+// result is returned by Picked.
+func NewPicker(paths []string, cfg config.Config, opts PickOptions) (Model, error) {
+	m, err := NewModel(paths, cfg)
+	if err != nil {
+		return m, err
+	}
+	m.picker = &opts
+	m.statusMsg = opts.hint()
+
+	// Enter picks instead of opening, earlier bindings taking precedence
+	pick := key.NewBinding(key.WithKeys(m.keys.Enter.Keys()...), key.WithHelp("enter", "pick and quit"))
+	defaults := append([]KeyCommand{{pick, "pick"}}, m.keys.Commands()...)
+	m.bindings, err = newKeyBindings(defaults, cfg.Keys)
+	if err != nil {
+		return m, fmt.Errorf("key bindings:\n  %w", err)
+	}
+	return m, nil
+}
+
+// Picked returns the paths picked before quitting, none if cancelled
+func (m Model) Picked() []string {
+	return m.picked
+}
+
+// pick quits with the marked entries, or the one under the cursor. A
+// directory under the cursor is entered unless only directories can be
+// picked.
+func (m Model) pick() (Model, tea.Cmd) {
+	if m.picker == nil {
+		return m.fail(fmt.Errorf("not picking files, start sushi with -pick")), nil
+	}
+	opts := *m.picker
+
+	if len(m.selected) > 0 {
+		if len(m.selected) > 1 && !opts.Multiple {
+			return m.fail(fmt.Errorf("only one entry can be picked, %d are marked", len(m.selected))), nil
+		}
+		for _, file := range m.selected {
+			if !opts.accepts(file) {
+				return m.fail(fmt.Errorf("cannot pick %s: %s", file.Name, pickKind(opts))), nil
+			}
+		}
+		m.picked = m.MarkedPaths()
+		return m.quit()
+	}
+
+	if len(m.files) == 0 {
+		return m, nil
+	}
+	file := m.files[m.cursor]
+	if file.IsDir && !opts.DirsOnly {
+		return m.enterDir(file.Path, "")
+	}
+	if !opts.accepts(file) {
+		return m.fail(fmt.Errorf("cannot pick %s: %s", file.Name, pickKind(opts))), nil
+	}
+	m.picked = []string{file.Path}
+	return m.quit()
+}
+
+// pickKind explains which entries can be picked
+func pickKind(opts PickOptions) string {
+	if opts.DirsOnly {
+		return "only directories can be picked"
+	}
+	return "only files can be picked"
+}
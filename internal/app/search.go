@@ -0,0 +1,157 @@
+package app
+
+import (
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/fs"
+	"github.com/icichainz/sushi/internal/utils"
+)
+
+// newSearchInput creates the text input used by ModeSearch
+func newSearchInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = "/"
+	input.Placeholder = "fuzzy filter"
+	return input
+}
+
+// setFiles replaces the directory listing, re-applying the sort order and
+// any active filter, and keeping the cursor on the same file when possible
+func (m Model) setFiles(files []fs.FileInfo) Model {
+	fs.SortFiles(files, m.order)
+	m.allFiles = files
+	return m.applyFilter()
+}
+
+// applyFilter narrows allFiles down to the entries matching the filter
+func (m Model) applyFilter() Model {
+	chosen := m.cursorPath()
+
+	visible := m.visibleFiles(m.allFiles)
+	m.hidden = len(m.allFiles) - len(visible)
+
+	files := visible
+	if m.treeView {
+		files = m.tree.flatten(visible, m.order, m.visibleFiles)
+	}
+
+	if m.filter == "" {
+		m.files = files
+		m.matches = nil
+	} else {
+		// Entries of expanded directories are matched too, keeping the
+		// directories leading to them
+		m.matches = make(map[string][]int)
+		keep := make(map[string]bool)
+		for _, file := range files {
+			if _, positions, ok := utils.FuzzyMatch(m.filter, file.Name); ok {
+				m.matches[file.Path] = positions
+				for dir := file.Path; dir != m.currentPath && !keep[dir]; dir = filepath.Dir(dir) {
+					keep[dir] = true
+				}
+			}
+		}
+		m.files = make([]fs.FileInfo, 0, len(m.matches))
+		for _, file := range files {
+			if keep[file.Path] {
+				m.files = append(m.files, file)
+			}
+		}
+	}
+
+	m.cursor = 0
+	return m.focusPath(chosen)
+}
+
+// focusPath moves the cursor to path if it is listed
+func (m Model) focusPath(path string) Model {
+	for i, file := range m.files {
+		if file.Path == path {
+			m.cursor = i
+			break
+		}
+	}
+	return m
+}
+
+// cursorPath returns the path under the cursor, or "" for an empty list
+func (m Model) cursorPath() string {
+	if m.cursor < 0 || m.cursor >= len(m.files) {
+		return ""
+	}
+	return m.files[m.cursor].Path
+}
+
+// startSearch enters ModeSearch, editing the current filter
+func (m Model) startSearch() (Model, tea.Cmd) {
+	m.mode = ModeSearch
+	m.searchInput.SetValue(m.filter)
+	m.searchInput.CursorEnd()
+	return m, m.searchInput.Focus()
+}
+
+// clearFilter drops the filter, restoring the full listing with the cursor
+// left on the chosen file
+func (m Model) clearFilter() (Model, tea.Cmd) {
+	m.mode = ModeNormal
+	m.filter = ""
+	m.searchInput.Blur()
+	m.searchInput.SetValue("")
+	m = m.applyFilter()
+	return m, m.previewCursor()
+}
+
+// handleSearchKey processes keyboard input while typing a filter
+func (m Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyEsc:
+		return m.clearFilter()
+
+	case msg.Type == tea.KeyEnter:
+		m.mode = ModeNormal
+		m.searchInput.Blur()
+		if m.filter == "" {
+			return m.clearFilter()
+		}
+		return m, nil
+
+	case msg.Type == tea.KeyUp, msg.Type == tea.KeyCtrlP:
+		return m.moveCursor(-1)
+
+	case msg.Type == tea.KeyDown, msg.Type == tea.KeyCtrlN:
+		return m.moveCursor(1)
+	}
+
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	if m.searchInput.Value() == m.filter {
+		return m, cmd
+	}
+
+	m.filter = m.searchInput.Value()
+	m = m.applyFilter()
+	return m, tea.Batch(cmd, m.previewCursor())
+}
+
+// jumpMatch moves the cursor to the next or previous match, wrapping around
+func (m Model) jumpMatch(delta int) (Model, tea.Cmd) {
+	if m.filter == "" {
+		m.statusMsg = "No active search"
+		return m, nil
+	}
+	if len(m.files) == 0 {
+		m.statusMsg = "No matches for " + m.filter
+		return m, nil
+	}
+
+	// Skip the directories only listed for the matches below them
+	for range m.files {
+		m.cursor = (m.cursor + delta + len(m.files)) % len(m.files)
+		if _, ok := m.matches[m.files[m.cursor].Path]; ok {
+			break
+		}
+	}
+	return m, m.previewCursor()
+}
@@ -0,0 +1,181 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/fs"
+)
+
+// visualState tracks a range selection, active in ModeVisual
+type visualState struct {
+	anchor string                 // Path where the range started
+	base   map[string]fs.FileInfo // Selection before the range, restored on cancel
+}
+
+// clearSelection drops all marked entries and leaves visual mode
+func (m Model) clearSelection() Model {
+	m.selected = make(map[string]fs.FileInfo)
+	if m.mode == ModeVisual {
+		m.mode = ModeNormal
+	}
+	m.visual = visualState{}
+	return m
+}
+
+// toggleSelect marks or unmarks the file under the cursor and moves down
+func (m Model) toggleSelect() (Model, tea.Cmd) {
+	if len(m.files) == 0 {
+		return m, nil
+	}
+
+	file := m.files[m.cursor]
+	if _, ok := m.selected[file.Path]; ok {
+		delete(m.selected, file.Path)
+	} else {
+		m.selected[file.Path] = file
+	}
+	return m.moveCursor(1)
+}
+
+// selectAll marks every listed file
+func (m Model) selectAll() (Model, tea.Cmd) {
+	for _, file := range m.files {
+		m.selected[file.Path] = file
+	}
+	m.statusMsg = fmt.Sprintf("Selected %d files", len(m.files))
+	return m, nil
+}
+
+// invertSelection toggles the mark on every listed file
+func (m Model) invertSelection() (Model, tea.Cmd) {
+	for _, file := range m.files {
+		if _, ok := m.selected[file.Path]; ok {
+			delete(m.selected, file.Path)
+		} else {
+			m.selected[file.Path] = file
+		}
+	}
+	return m, nil
+}
+
+// selectPattern marks (or unmarks) listed files whose name matches a glob,
+// or a regular expression written as /expr/
+func (m Model) selectPattern(pattern string, selected bool) (Model, tea.Cmd) {
+	match, err := compilePattern(pattern)
+	if err != nil {
+		return m.fail(err), nil
+	}
+
+	count := 0
+	for _, file := range m.files {
+		if !match(file.Name) {
+			continue
+		}
+		if selected {
+			m.selected[file.Path] = file
+		} else {
+			delete(m.selected, file.Path)
+		}
+		count++
+	}
+
+	verb := "Selected"
+	if !selected {
+		verb = "Deselected"
+	}
+	m.statusMsg = fmt.Sprintf("%s %d files matching %s", verb, count, pattern)
+	return m, nil
+}
+
+// compilePattern turns a glob or /regex/ into a name matcher
+func compilePattern(pattern string) (func(string) bool, error) {
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("bad pattern %q: %w", pattern, err)
+	}
+	return func(name string) bool {
+		ok, _ := filepath.Match(pattern, name)
+		return ok
+	}, nil
+}
+
+// toggleVisual starts a range selection at the cursor, or ends the current
+// one keeping its entries marked
+func (m Model) toggleVisual() (Model, tea.Cmd) {
+	if m.mode == ModeVisual {
+		m.mode = ModeNormal
+		m.visual = visualState{}
+		return m, nil
+	}
+	if len(m.files) == 0 {
+		return m, nil
+	}
+
+	m.mode = ModeVisual
+	m.visual = visualState{
+		anchor: m.cursorPath(),
+		base:   copySelection(m.selected),
+	}
+	return m.extendVisual(), nil
+}
+
+// cancelVisual ends a range selection, restoring the previous marks
+func (m Model) cancelVisual() Model {
+	m.selected = m.visual.base
+	m.visual = visualState{}
+	m.mode = ModeNormal
+	return m
+}
+
+// extendVisual marks everything between the anchor and the cursor on top
+// of the selection that existed when visual mode started
+func (m Model) extendVisual() Model {
+	anchor := -1
+	for i, file := range m.files {
+		if file.Path == m.visual.anchor {
+			anchor = i
+			break
+		}
+	}
+	// The anchor disappeared, e.g. after changing directory
+	if anchor < 0 {
+		m.mode = ModeNormal
+		m.visual = visualState{}
+		return m
+	}
+
+	m.selected = copySelection(m.visual.base)
+	for i := min(anchor, m.cursor); i <= max(anchor, m.cursor); i++ {
+		m.selected[m.files[i].Path] = m.files[i]
+	}
+	return m
+}
+
+// selectionSize returns the total size of the marked entries
+func (m Model) selectionSize() int64 {
+	var total int64
+	for _, file := range m.selected {
+		total += file.Size
+	}
+	return total
+}
+
+// copySelection copies a selection so it can be restored later
+func copySelection(selected map[string]fs.FileInfo) map[string]fs.FileInfo {
+	copied := make(map[string]fs.FileInfo, len(selected))
+	for path, file := range selected {
+		copied[path] = file
+	}
+	return copied
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/icichainz/sushi/internal/fs"
+	"github.com/icichainz/sushi/internal/utils"
+)
+
+// sortOrdersPath is where the order chosen for each directory is kept
+func sortOrdersPath() string {
+	return filepath.Join(utils.DataDir(), "sorting")
+}
+
+// sortMemory remembers the order chosen for each directory, saved after
+// every change as one "order<tab>path" line per directory
+type sortMemory struct {
+	path   string
+	orders map[string]fs.SortOrder
+}
+
+// loadSortMemory reads the orders stored at path, returning an empty
+// memory if the file does not exist yet
+func loadSortMemory(path string) (*sortMemory, error) {
+	s := &sortMemory{path: path, orders: make(map[string]fs.SortOrder)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+
+	var bad []int
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		spec, dir, ok := strings.Cut(line, "\t")
+		order, err := fs.ParseSortOrder(spec)
+		if !ok || err != nil || dir == "" {
+			bad = append(bad, n)
+			continue
+		}
+		s.orders[dir] = order
+	}
+	if len(bad) > 0 {
+		return s, fmt.Errorf("sort orders %s: malformed line(s) %v", path, bad)
+	}
+	return s, nil
+}
+
+// get returns the order chosen for dir
+func (s *sortMemory) get(dir string) (fs.SortOrder, bool) {
+	order, ok := s.orders[dir]
+	return order, ok
+}
+
+// set remembers order for dir
+func (s *sortMemory) set(dir string, order fs.SortOrder) error {
+	if strings.ContainsRune(dir, '\n') {
+		return nil // Can't be stored in the file
+	}
+	s.orders[dir] = order
+	return s.save()
+}
+
+// forget drops the order chosen for dir
+func (s *sortMemory) forget(dir string) error {
+	if _, ok := s.orders[dir]; !ok {
+		return nil
+	}
+	delete(s.orders, dir)
+	return s.save()
+}
+
+// save writes the orders atomically
+func (s *sortMemory) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	dirs := make([]string, 0, len(s.orders))
+	for dir := range s.orders {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var buf bytes.Buffer
+	for _, dir := range dirs {
+		fmt.Fprintf(&buf, "%s\t%s\n", s.orders[dir], dir)
+	}
+	return fs.WriteFileAtomic(s.path, buf.Bytes(), 0o600)
+}
+
+// orderFor returns the order dir was given, or the default one
+func (m Model) orderFor(dir string) fs.SortOrder {
+	if order, ok := m.sortOrders.get(dir); ok {
+		return order
+	}
+	return m.defaultOrder
+}
+
+// sortIndicator describes the active order for the status bar, with a
+// star when it was chosen for this directory
+func (m Model) sortIndicator() string {
+	s := "⇅ " + m.order.String()
+	if _, ok := m.sortOrders.get(m.currentPath); ok {
+		s += "*"
+	}
+	return s
+}
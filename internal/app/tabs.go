@@ -0,0 +1,173 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/fs"
+	"github.com/icichainz/sushi/internal/ui/components"
+)
+
+// tabState is the part of the model each tab has its own copy of. The
+// active tab lives in the Model fields; the others wait in Model.tabs.
+type tabState struct {
+	id          int
+	currentPath string
+	allFiles    []fs.FileInfo
+	files       []fs.FileInfo
+	cursor      int
+	selected    map[string]fs.FileInfo
+	order       fs.SortOrder
+	filter      string
+	matches     map[string][]int
+	hidden      int
+	history     []string
+	historyPos  int
+}
+
+// newTab creates the state of a tab opened at path, sorted by order
+func newTab(id int, path string, order fs.SortOrder) tabState {
+	return tabState{
+		id:          id,
+		currentPath: path,
+		selected:    make(map[string]fs.FileInfo),
+		order:       order,
+	}
+}
+
+// saveTab stores the active tab's state in m.tabs
+func (m Model) saveTab() Model {
+	m.tabs[m.activeTab] = tabState{
+		id:          m.tabID,
+		currentPath: m.currentPath,
+		allFiles:    m.allFiles,
+		files:       m.files,
+		cursor:      m.cursor,
+		selected:    m.selected,
+		order:       m.order,
+		filter:      m.filter,
+		matches:     m.matches,
+		hidden:      m.hidden,
+		history:     m.history,
+		historyPos:  m.historyPos,
+	}
+	return m
+}
+
+// loadTab makes tab i the active one
+func (m Model) loadTab(i int) Model {
+	t := m.tabs[i]
+	m.activeTab = i
+	m.tabID = t.id
+	m.currentPath = t.currentPath
+	m.allFiles = t.allFiles
+	m.files = t.files
+	m.cursor = t.cursor
+	m.selected = t.selected
+	m.order = t.order
+	m.filter = t.filter
+	m.matches = t.matches
+	m.hidden = t.hidden
+	m.history = t.history
+	m.historyPos = t.historyPos
+	return m
+}
+
+// switchTab activates tab i and reloads its directory, which may have
+// changed while it was in the background
+func (m Model) switchTab(i int) (Model, tea.Cmd) {
+	if i == m.activeTab || i < 0 || i >= len(m.tabs) {
+		return m, nil
+	}
+	// A range selection belongs to the tab it started in
+	if m.mode == ModeVisual {
+		m.mode = ModeNormal
+		m.visual = visualState{}
+	}
+	// Focusing the other pane's tab swaps the panes
+	if i == m.otherTab && m.activeTab >= 0 {
+		m.otherTab = m.activeTab
+	}
+
+	if m.activeTab >= 0 {
+		m = m.saveTab()
+	}
+	m = m.loadTab(i)
+	m.preview = components.PreviewContent{}
+	return m, m.loadDirectoryAt(m.currentPath, m.cursorPath())
+}
+
+// newTabAt opens a tab at path right after the active one
+func (m Model) newTabAt(path string) (Model, tea.Cmd) {
+	m = m.saveTab()
+	m.nextTabID++
+	tab := newTab(m.nextTabID, path, m.orderFor(path))
+
+	at := m.activeTab + 1
+	m.tabs = append(m.tabs[:at], append([]tabState{tab}, m.tabs[at:]...)...)
+	if m.otherTab >= at {
+		m.otherTab++
+	}
+	m.activeTab = -1 // Nothing to save when switching away
+	return m.switchTab(at)
+}
+
+// closeTab closes the active tab, moving to its neighbour
+func (m Model) closeTab() (Model, tea.Cmd) {
+	if len(m.tabs) == 1 {
+		m.statusMsg = "Cannot close the last tab"
+		return m, nil
+	}
+
+	closed := m.activeTab
+	m.tabs = append(m.tabs[:closed], m.tabs[closed+1:]...)
+	m.activeTab = -1
+	if m.otherTab > closed {
+		m.otherTab--
+	}
+	next := min(closed, len(m.tabs)-1)
+
+	// In the dual layout the focus moves to the other pane, which gets a
+	// new neighbour if there is one left
+	if m.layout == layoutDual {
+		if len(m.tabs) == 1 {
+			m.layout = layoutSingle
+			return m.switchTab(0)
+		}
+		next = m.otherTab
+		m.otherTab = min(closed, len(m.tabs)-1)
+		if m.otherTab == next {
+			m.otherTab = (next + 1) % len(m.tabs)
+		}
+		m, cmd := m.switchTab(next)
+		return m, tea.Batch(cmd, m.loadOtherPane())
+	}
+	return m.switchTab(next)
+}
+
+func cmdTabNew(m Model, args []string) (Model, tea.Cmd) {
+	path := m.currentPath
+	if len(args) > 0 {
+		path = m.resolvePath(args[0])
+	}
+	return m.newTabAt(path)
+}
+
+func cmdTab(m Model, args []string) (Model, tea.Cmd) {
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(m.tabs) {
+		return m.fail(fmt.Errorf("no tab %s", args[0])), nil
+	}
+	return m.switchTab(n - 1)
+}
+
+// title is the label of a tab in the tab bar
+func (t tabState) title() string {
+	name := filepath.Base(t.currentPath)
+	if name == string(filepath.Separator) || name == "." {
+		return t.currentPath
+	}
+	return name
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/fs"
+)
+
+// trashLoadedMsg is sent when the trash contents have been listed
+type trashLoadedMsg struct {
+	items []fs.TrashItem
+	err   error
+}
+
+// loadTrash lists all trash directories asynchronously
+func loadTrash() tea.Cmd {
+	return func() tea.Msg {
+		items, err := fs.ListTrash()
+		return trashLoadedMsg{items: items, err: err}
+	}
+}
+
+// openTrash switches to the trash browser
+func (m Model) openTrash() (Model, tea.Cmd) {
+	m.mode = ModeTrash
+	m.cursor = 0
+	m.files = nil
+	m.visual = visualState{}
+	m.keptMarks = m.selected // Trashed entries get marks of their own
+	m.selected = make(map[string]fs.FileInfo)
+	m.statusMsg = "r restore · d purge · esc back"
+	return m, loadTrash()
+}
+
+// closeTrash leaves the trash browser and reloads the current directory
+func (m Model) closeTrash() (Model, tea.Cmd) {
+	m.mode = ModeNormal
+	m.trashItems = nil
+	m.selected = m.keptMarks
+	m.keptMarks = nil
+	m.statusMsg = ""
+	return m, m.loadDirectory(m.currentPath)
+}
+
+// handleTrashLoaded shows the trash contents using the regular file list,
+// with the original location as name and the deletion date as time
+func (m Model) handleTrashLoaded(msg trashLoadedMsg) (Model, tea.Cmd) {
+	if m.mode != ModeTrash {
+		return m, nil
+	}
+
+	m.trashItems = msg.items
+	m.err = msg.err
+	m.files = make([]fs.FileInfo, len(msg.items))
+	for i, item := range msg.items {
+		file := item.FileInfo
+		file.Name = item.OriginalPath
+		file.ModTime = item.DeletionDate
+		m.files[i] = file
+	}
+	m.cursor = min(m.cursor, max(0, len(m.files)-1))
+	return m, m.previewCursor()
+}
+
+// handleTrashKey processes keyboard input in the trash browser
+func (m Model) handleTrashKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m, command := m.resolveKey(msg)
+	switch name, _ := commandName(command); name {
+	case "quit", "up", "down", "top", "bottom", "toggle-select":
+		return m.execute(command)
+
+	case "trash", "delete":
+		paths := m.targets()
+		if len(paths) == 0 {
+			return m, nil
+		}
+		prompt := fmt.Sprintf("Purge %s from the trash?", describePaths(paths))
+		return m.askConfirm(prompt, func(m Model) (Model, tea.Cmd) {
+			m = m.clearSelection()
+			return m.enqueueJob(jobPurge, paths, "")
+		})
+
+	case "cancel", "trash-browser", "parent", "back":
+		return m.closeTrash()
+	}
+
+	// Keys of the trash browser itself, unless bound to one of the above
+	if command == "" && len(m.pending) == 0 && key.Matches(msg, m.keys.Restore) {
+		paths := m.targets()
+		if len(paths) == 0 {
+			return m, nil
+		}
+		m = m.clearSelection()
+		return m.enqueueJob(jobRestore, paths, "")
+	}
+	return m, nil
+}
@@ -0,0 +1,213 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/fs"
+)
+
+// treeState remembers which directories are expanded in the tree view and
+// what they contain. It is keyed by path and shared by all tabs, so a
+// directory stays expanded when coming back to it.
+type treeState struct {
+	expanded map[string]bool
+	children map[string][]fs.FileInfo
+}
+
+// newTreeState creates a tree with nothing expanded
+func newTreeState() *treeState {
+	return &treeState{
+		expanded: make(map[string]bool),
+		children: make(map[string][]fs.FileInfo),
+	}
+}
+
+// expandedUnder lists the expanded directories below root whose ancestors
+// are all expanded too, parents first
+func (t *treeState) expandedUnder(root string) []string {
+	var paths []string
+	for path := range t.expanded {
+		if !strings.HasPrefix(path, root+string(filepath.Separator)) {
+			continue
+		}
+		visible := true
+		for dir := filepath.Dir(path); dir != root && visible; dir = filepath.Dir(dir) {
+			visible = t.expanded[dir]
+		}
+		if visible {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// flatten inserts the visible contents of expanded directories after them,
+// recursively
+func (t *treeState) flatten(files []fs.FileInfo, order fs.SortOrder, visible func([]fs.FileInfo) []fs.FileInfo) []fs.FileInfo {
+	out := make([]fs.FileInfo, 0, len(files))
+	for _, file := range files {
+		out = append(out, file)
+		children, ok := t.children[file.Path]
+		if !file.IsDir || !t.expanded[file.Path] || !ok {
+			continue
+		}
+		children = slices.Clone(visible(children))
+		fs.SortFiles(children, order)
+		out = append(out, t.flatten(children, order, visible)...)
+	}
+	return out
+}
+
+// scanExpanded reads the given expanded directories, skipping the ones that
+// are gone
+func scanExpanded(paths []string) map[string][]fs.FileInfo {
+	if len(paths) == 0 {
+		return nil
+	}
+	children := make(map[string][]fs.FileInfo, len(paths))
+	for _, path := range paths {
+		if files, err := fs.ScanDirectory(path); err == nil {
+			children[path] = files
+		}
+	}
+	return children
+}
+
+// treeLoadedMsg is sent when a directory expanded in the tree view has
+// been scanned
+type treeLoadedMsg struct {
+	path  string
+	files []fs.FileInfo
+	err   error
+}
+
+// loadTreeChildren scans a directory being expanded
+func loadTreeChildren(path string) tea.Cmd {
+	return func() tea.Msg {
+		files, err := fs.ScanDirectory(path)
+		return treeLoadedMsg{path: path, files: files, err: err}
+	}
+}
+
+// handleTreeLoaded shows the contents of a newly expanded directory
+func (m Model) handleTreeLoaded(msg treeLoadedMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		delete(m.tree.expanded, msg.path)
+		return m.fail(msg.err), nil
+	}
+	m.tree.children[msg.path] = msg.files
+	if !m.treeView {
+		return m, nil
+	}
+	m = m.watchShown()
+
+	m = m.applyFilter()
+	if other := m.otherPane(); other >= 0 {
+		m = m.applyToOtherPane(func(m Model) Model {
+			return m.applyFilter()
+		})
+	}
+	return m, nil
+}
+
+// setTreeView turns the tree view on or off
+func (m Model) setTreeView(enabled bool) (Model, tea.Cmd) {
+	m.treeView = enabled
+	m.statusMsg = "Tree view " + onOff(enabled)
+	m = m.applyFilter()
+	return m, tea.Batch(m.refresh(), m.previewCursor())
+}
+
+// toggleExpand expands or collapses the directory under the cursor. On a
+// nested file it collapses the directory containing it.
+func (m Model) toggleExpand() (Model, tea.Cmd) {
+	if len(m.files) == 0 {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	if !m.treeView {
+		m, cmd = m.setTreeView(true)
+	}
+
+	file := m.files[m.cursor]
+	switch {
+	case file.Loop && !m.tree.expanded[file.Path]:
+		return m.fail(fmt.Errorf("not expanding %s, a %s", file.Name, file.Description())), cmd
+
+	case file.IsDir && !m.tree.expanded[file.Path]:
+		m.tree.expanded[file.Path] = true
+		m = m.applyFilter()
+		return m, tea.Batch(cmd, loadTreeChildren(file.Path))
+
+	case file.IsDir:
+		delete(m.tree.expanded, file.Path)
+
+	case filepath.Dir(file.Path) != m.currentPath:
+		delete(m.tree.expanded, filepath.Dir(file.Path))
+		m = m.applyFilter().focusPath(filepath.Dir(file.Path))
+		return m, tea.Batch(cmd, m.previewCursor())
+	}
+	return m.applyFilter(), cmd
+}
+
+// collapseAll collapses every directory below the current one
+func (m Model) collapseAll() (Model, tea.Cmd) {
+	for path := range m.tree.expanded {
+		if strings.HasPrefix(path, m.currentPath+string(filepath.Separator)) {
+			delete(m.tree.expanded, path)
+		}
+	}
+
+	// Land on the top level ancestor of the entry under the cursor
+	top := m.cursorPath()
+	for top != "" && filepath.Dir(top) != m.currentPath && filepath.Dir(top) != top {
+		top = filepath.Dir(top)
+	}
+	m = m.applyFilter().focusPath(top)
+	return m, m.previewCursor()
+}
+
+// treeGuides returns the indentation drawn before each file in the tree
+// view, computed from the end of the list so each entry knows whether more
+// siblings follow it and its ancestors
+func treeGuides(files []fs.FileInfo, root string) []string {
+	guides := make([]string, len(files))
+	var more []bool // Whether a later entry sits at each depth
+
+	for i := len(files) - 1; i >= 0; i-- {
+		rel, err := filepath.Rel(root, files[i].Path)
+		if err != nil {
+			continue
+		}
+		depth := strings.Count(rel, string(filepath.Separator))
+		for len(more) <= depth {
+			more = append(more, false)
+		}
+
+		if depth > 0 {
+			var b strings.Builder
+			for k := 1; k < depth; k++ {
+				if more[k] {
+					b.WriteString("│  ")
+				} else {
+					b.WriteString("   ")
+				}
+			}
+			if more[depth] {
+				b.WriteString("├─ ")
+			} else {
+				b.WriteString("└─ ")
+			}
+			guides[i] = b.String()
+		}
+
+		more[depth] = true
+		more = more[:depth+1]
+	}
+	return guides
+}
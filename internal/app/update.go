@@ -1,9 +1,6 @@
 package app
 
 import (
-	"path/filepath"
-
-	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/icichainz/sushi/internal/fs"
 	"github.com/icichainz/sushi/internal/ui/components"
@@ -21,86 +18,215 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case dirLoadedMsg:
-		m.files = msg.files
-		m.currentPath = msg.path
-		m.cursor = 0
+		// The trash browser owns the listing until it is closed. The other
+		// pane takes its own listings; the remaining tabs are reloaded when
+		// switching to them.
+		if m.mode == ModeTrash {
+			return m, nil
+		}
+		if msg.err == nil {
+			m.listings.put(msg.path, msg.files)
+			m.ignores.Forget(msg.path) // Its ignore files may have changed
+		}
+		for path, files := range msg.children {
+			m.tree.children[path] = files
+		}
+		if other := m.otherPane(); other >= 0 && msg.tab == m.tabs[other].id {
+			m = m.applyToOtherPane(func(m Model) Model {
+				return m.applyListing(msg)
+			})
+			m = m.watchShown()
+			return m, nil
+		}
+		if msg.tab != m.tabID {
+			return m, nil
+		}
+
+		m = m.applyListing(msg)
 		m.err = msg.err
-		
-		// Load preview for first file
-		if len(m.files) > 0 && m.previewEnabled {
-			return m, loadPreviewWithModel(m.files[0], m)
+		if msg.visitErr != nil {
+			m.statusMsg = "Visit not recorded for :z: " + msg.visitErr.Error()
+		}
+		if m.mode == ModeVisual {
+			m = m.extendVisual()
+		}
+		m = m.watchShown()
+
+		// Load preview for the file under the cursor
+		return m, tea.Batch(m.previewCursor(), m.loadParentColumn())
+
+	case treeLoadedMsg:
+		return m.handleTreeLoaded(msg)
+
+	case listingLoadedMsg:
+		if msg.err == nil {
+			m.listings.put(msg.path, msg.files)
 		}
 		return m, nil
 
 	case previewLoadedMsg:
 		m.preview = msg.preview
+		m = m.watchShown()
 		return m, nil
+
+	case fsChangedMsg:
+		return m.applyChanges(msg)
+
+	case jobProgressMsg:
+		return m.handleJobProgress(msg)
+
+	case jobDoneMsg:
+		return m.handleJobDone(msg)
+
+	case trashLoadedMsg:
+		return m.handleTrashLoaded(msg)
+
+	case journalMsg:
+		return m.handleJournal(msg)
+
+	case finderBatchMsg:
+		return m.handleFinderBatch(msg)
+
+	case finderScoredMsg:
+		return m.handleFinderScored(msg)
+
+	case bulkRenameMsg:
+		return m.handleBulkRename(msg)
+
+	case openedMsg:
+		return m.handleOpened(msg)
 	}
 
-	return m, nil
+	// Let the active text input animate its cursor
+	var cmd tea.Cmd
+	switch m.mode {
+	case ModeSearch:
+		m.searchInput, cmd = m.searchInput.Update(msg)
+	case ModeFinder:
+		m.finder.input, cmd = m.finder.input.Update(msg)
+	case ModeCommand:
+		m.cmdline.input, cmd = m.cmdline.input.Update(msg)
+	}
+	return m, cmd
 }
 
 // handleKeyPress processes keyboard input
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch {
-	case key.Matches(msg, m.keys.Quit):
-		return m, tea.Quit
+	switch m.mode {
+	case ModeConfirm:
+		return m.handleConfirmKey(msg)
+	case ModeTrash:
+		return m.handleTrashKey(msg)
+	case ModeSearch:
+		return m.handleSearchKey(msg)
+	case ModeFinder:
+		return m.handleFinderKey(msg)
+	case ModeCommand:
+		return m.handleCommandKey(msg)
+	case ModeMenu:
+		return m.handleMenuKey(msg)
+	case ModeHelp:
+		return m.handleHelpKey(msg)
+	case ModeBookmarks:
+		return m.handleBookmarksKey(msg)
+	}
 
-	case key.Matches(msg, m.keys.Preview):
-		m.previewEnabled = !m.previewEnabled
-		m.statusMsg = "Preview toggled"
+	m, command := m.resolveKey(msg)
+	if command == "" {
 		return m, nil
+	}
+	m, cmd := m.execute(command)
+	// Grow the range selection with the cursor
+	if m.mode == ModeVisual {
+		m = m.extendVisual()
+	}
+	return m, cmd
+}
 
-	case key.Matches(msg, m.keys.ToggleSyntax):
-		m.syntaxHighlight = !m.syntaxHighlight
-		if m.syntaxHighlight {
-			m.statusMsg = "Syntax highlighting enabled"
-		} else {
-			m.statusMsg = "Syntax highlighting disabled"
-		}
-		// Reload current preview with new setting
-		if len(m.files) > 0 && m.previewEnabled {
-			return m, loadPreviewWithModel(m.files[m.cursor], m)
-		}
+// resolveKey adds the key to the sequence being typed, returning the
+// command line bound to it in the current mode once complete. A key that
+// breaks off a sequence is looked up again on its own, as if typed first.
+func (m Model) resolveKey(msg tea.KeyMsg) (Model, string) {
+	seq := append(m.pending[:len(m.pending):len(m.pending)], msg.String())
+	command, ok, prefix := m.activeBindings().lookup(seq)
+	switch {
+	case prefix:
+		// Wait for the rest of the sequence
+		m.pending = seq
+		return m, ""
+	case !ok && len(m.pending) > 0:
+		m.pending = nil
+		return m.resolveKey(msg)
+	}
+
+	m.pending = nil
+	return m, command
+}
+
+// moveCursor moves the cursor by delta and loads the new preview
+func (m Model) moveCursor(delta int) (Model, tea.Cmd) {
+	cursor := max(0, min(len(m.files)-1, m.cursor+delta))
+	if len(m.files) == 0 || cursor == m.cursor {
 		return m, nil
+	}
+	m.cursor = cursor
+	return m, m.previewCursor()
+}
 
-	case key.Matches(msg, m.keys.Up):
-		if m.cursor > 0 {
-			m.cursor--
-			if len(m.files) > 0 && m.previewEnabled {
-				return m, loadPreviewWithModel(m.files[m.cursor], m)
-			}
-		}
+// previewCursor loads the preview of the file under the cursor
+func (m Model) previewCursor() tea.Cmd {
+	if len(m.files) == 0 || !m.previewEnabled {
+		return nil
+	}
+	return loadPreviewWithModel(m.files[m.cursor], m)
+}
 
-	case key.Matches(msg, m.keys.Down):
-		if m.cursor < len(m.files)-1 {
-			m.cursor++
-			if len(m.files) > 0 && m.previewEnabled {
-				return m, loadPreviewWithModel(m.files[m.cursor], m)
-			}
-		}
+// refresh reloads whatever listing is currently shown
+func (m Model) refresh() tea.Cmd {
+	if m.mode == ModeTrash {
+		return loadTrash()
+	}
+	return tea.Batch(m.loadDirectory(m.currentPath), m.loadOtherPane())
+}
 
-	case key.Matches(msg, m.keys.Right), key.Matches(msg, m.keys.Enter):
-		if len(m.files) > 0 && m.files[m.cursor].IsDir {
-			newPath := m.files[m.cursor].Path
-			return m, loadDirectory(newPath)
+// applyListing shows a loaded directory, keeping the cursor and filter
+// when it is the one already shown
+func (m Model) applyListing(msg dirLoadedMsg) Model {
+	focus := msg.focus
+	if msg.path != m.currentPath {
+		// Remember where we were, and come back there next time
+		if path := m.cursorPath(); path != "" {
+			m.lastCursor[m.currentPath] = path
 		}
-
-	case key.Matches(msg, m.keys.Left), key.Matches(msg, m.keys.Back):
-		parentPath := filepath.Dir(m.currentPath)
-		if parentPath != m.currentPath {
-			return m, loadDirectory(parentPath)
+		if focus == "" {
+			focus = m.lastCursor[msg.path]
 		}
+		m.cursor = 0
+		m.filter = ""
+		m.files = nil
+		m.order = m.orderFor(msg.path)
 	}
-
-	return m, nil
+	if msg.path != m.currentPath || len(m.history) == 0 {
+		m = m.visit(msg.path)
+	}
+	m.currentPath = msg.path
+	m = m.setFiles(msg.files)
+	return m.focusPath(focus)
 }
 
 // dirLoadedMsg is sent when a directory has been loaded
 type dirLoadedMsg struct {
+	tab   int // Tab that asked for the listing
 	path  string
 	files []fs.FileInfo
+	focus string // Path to place the cursor on, if any
 	err   error
+
+	// Recording the visit in the frecency database failed
+	visitErr error
+
+	// Contents of the directories expanded below path in the tree view
+	children map[string][]fs.FileInfo
 }
 
 // previewLoadedMsg is sent when preview content has been loaded
@@ -109,26 +235,44 @@ type previewLoadedMsg struct {
 }
 
 // loadDirectory loads files from a directory asynchronously
-func loadDirectory(path string) tea.Cmd {
+func (m Model) loadDirectory(path string) tea.Cmd {
+	return m.loadDirectoryAt(path, "")
+}
+
+// loadDirectoryAt loads a directory and places the cursor on focus. Moving
+// to another directory counts as a visit in the frecency database.
+func (m Model) loadDirectoryAt(path, focus string) tea.Cmd {
+	tab := m.tabID
+	var expanded []string
+	if m.treeView {
+		expanded = m.tree.expandedUnder(path)
+	}
+	db := m.frecency
+	if path == m.currentPath {
+		db = nil
+	}
 	return func() tea.Msg {
 		files, err := fs.ScanDirectory(path)
+		var visitErr error
+		if err == nil && db != nil {
+			visitErr = db.Visit(path)
+		}
 		return dirLoadedMsg{
-			path:  path,
-			files: files,
-			err:   err,
+			tab:      tab,
+			path:     path,
+			files:    files,
+			children: scanExpanded(expanded),
+			focus:    focus,
+			err:      err,
+			visitErr: visitErr,
 		}
 	}
 }
 
 // loadPreviewWithModel loads preview content with model's configuration
 func loadPreviewWithModel(file fs.FileInfo, m Model) tea.Cmd {
+	config := m.previewConfig()
 	return func() tea.Msg {
-		config := components.PreviewConfig{
-			MaxLines:        100,
-			SyntaxHighlight: m.syntaxHighlight,
-			SyntaxTheme:     m.syntaxTheme,
-			MaxPreviewSize:  10 * 1024 * 1024,
-		}
 		preview := components.LoadPreviewWithConfig(file, config)
 		return previewLoadedMsg{
 			preview: preview,
@@ -2,6 +2,7 @@ package app
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -22,13 +23,32 @@ func (m Model) View() string {
 	// Header with current path
 	sections = append(sections, m.renderHeader())
 
-	// Main content: file list + preview (if enabled)
-	if m.previewEnabled {
+	// Main content: finder, or file list + preview (if enabled)
+	if m.mode == ModeFinder {
+		sections = append(sections, m.renderFinder())
+	} else if m.mode == ModeHelp {
+		sections = append(sections, m.renderHelp())
+	} else if m.mode == ModeBookmarks {
+		sections = append(sections, m.renderBookmarks())
+	} else if m.mode == ModeMenu {
+		sections = append(sections, m.renderMenu())
+	} else if m.mode == ModeConfirm && len(m.confirm.details) > 0 {
+		sections = append(sections, m.renderConfirmDetails())
+	} else if m.otherPane() >= 0 && m.mode != ModeTrash {
+		sections = append(sections, m.renderDualView())
+	} else if m.layout == layoutColumns && m.mode != ModeTrash {
+		sections = append(sections, m.renderColumnsView())
+	} else if m.previewEnabled {
 		sections = append(sections, m.renderSplitView())
 	} else {
 		sections = append(sections, m.renderFileList(m.width))
 	}
 
+	// Hint how the key sequence being typed can continue
+	if len(m.pending) > 0 {
+		sections[1] = m.overlayWhichKey(sections[1])
+	}
+
 	// Status bar
 	sections = append(sections, m.renderStatusBar())
 
@@ -38,7 +58,26 @@ func (m Model) View() string {
 // renderHeader renders the header with current path
 func (m Model) renderHeader() string {
 	pathStyle := m.styles.Header.Width(m.width)
-	return pathStyle.Render(fmt.Sprintf(" 📁 %s", m.currentPath))
+	if m.mode == ModeTrash {
+		return pathStyle.Render(fmt.Sprintf(" 🗑️  Trash (%d items)", len(m.trashItems)))
+	}
+	if len(m.tabs) < 2 {
+		return pathStyle.Render(fmt.Sprintf(" 📁 %s", m.currentPath))
+	}
+
+	// Tab bar, then the path in the remaining space
+	var bar strings.Builder
+	for i, tab := range m.tabs {
+		if i == m.activeTab {
+			tab.currentPath = m.currentPath // Stored state is stale while active
+			bar.WriteString(m.styles.SelectedFile.Render(fmt.Sprintf(" %d %s ", i+1, tab.title())))
+		} else {
+			bar.WriteString(m.styles.Header.UnsetPadding().Bold(false).Render(fmt.Sprintf(" %d %s ", i+1, tab.title())))
+		}
+	}
+	tabs := bar.String()
+	path := pathStyle.Width(max(0, m.width-lipgloss.Width(tabs))).Render(fmt.Sprintf(" 📁 %s", m.currentPath))
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(tabs + path)
 }
 
 // renderSplitView renders the split pane layout (file list + preview)
@@ -80,9 +119,18 @@ func (m Model) renderFileList(width int) string {
 		start = max(0, end-height)
 	}
 
+	var guides []string
+	if m.treeView {
+		guides = treeGuides(m.files, m.currentPath)
+	}
+
 	for i := start; i < end; i++ {
 		file := m.files[i]
-		line := m.renderFileLine(file, i == m.cursor, width)
+		guide := ""
+		if guides != nil {
+			guide = guides[i]
+		}
+		line := m.renderFileLine(file, guide, i == m.cursor, width)
 		lines = append(lines, line)
 	}
 
@@ -93,34 +141,45 @@ func (m Model) renderFileList(width int) string {
 		Render(listContent)
 }
 
-// renderFileLine renders a single file line
-func (m Model) renderFileLine(file fs.FileInfo, isCursor bool, width int) string {
+// renderFileLine renders a single file line, indented by its tree guide
+func (m Model) renderFileLine(file fs.FileInfo, guide string, isCursor bool, width int) string {
 	icon := ui.GetFileIcon(file)
+	if file.IsDir && m.treeView && m.tree.expanded[file.Path] {
+		icon = "📂"
+	}
 	name := file.Name
+	if file.IsLink {
+		name += " -> " + file.LinkTarget
+	}
 	
 	// Truncate name if too long
-	maxNameLen := width - 30 // Leave room for size and date
+	maxNameLen := width - 31 - lipgloss.Width(guide) // Leave room for the mark, icon, size and date
 	if maxNameLen < 10 {
 		maxNameLen = 10
 	}
-	if len(name) > maxNameLen {
-		name = name[:maxNameLen-3] + "..."
-	}
+	shown := truncateEnd(name, maxNameLen, "...")
+	truncated := shown != name
+	name = shown
 
 	size := utils.HumanizeSize(file.Size)
 	modTime := file.ModTime.Format("Jan 02 15:04")
 
+	// Marked entries get a bar in the first column
+	mark := " "
+	_, isMarked := m.selected[file.Path]
+	if isMarked {
+		mark = "▌"
+	}
+
 	// Build the line with proper spacing
-	namePart := fmt.Sprintf("%s  %-*s", icon, maxNameLen, name)
+	namePart := mark + guide + icon + "  " + padRight(name, maxNameLen)
 	sizePart := fmt.Sprintf("%10s", size)
 	timePart := fmt.Sprintf("  %s", modTime)
 	
 	line := namePart + sizePart + timePart
 
 	// Ensure line doesn't exceed width
-	if len(line) > width-2 {
-		line = line[:width-2]
-	}
+	line = truncateEnd(line, width-2, "")
 
 	// Apply styling
 	style := m.styles.File
@@ -130,10 +189,101 @@ func (m Model) renderFileLine(file fs.FileInfo, isCursor bool, width int) string
 	if file.IsDir {
 		style = style.Foreground(lipgloss.Color("12"))
 	}
+	if file.Broken {
+		style = m.styles.BrokenLink.Inherit(style.UnsetForeground())
+	} else if file.IsLink {
+		style = m.styles.Symlink.Inherit(style.UnsetForeground())
+	}
+	if isMarked {
+		style = m.styles.Marked.Inherit(style)
+	}
+
+	// Highlight the characters matched by the search filter
+	if positions := m.matches[file.Path]; len(positions) > 0 {
+		visible := len([]rune(name))
+		if truncated {
+			visible -= 3 // Don't highlight the ellipsis
+		}
+		offset := len([]rune(mark + guide + icon + "  "))
+
+		var marked []int
+		for _, p := range positions {
+			if p < visible {
+				marked = append(marked, p+offset)
+			}
+		}
+		return renderHighlighted(line, marked, style, m.styles.Match.Inherit(style))
+	}
 
 	return style.Render(line)
 }
 
+// renderHighlighted renders line with the runes at the given positions in
+// the highlight style and everything else in the base style
+func renderHighlighted(line string, positions []int, base, highlight lipgloss.Style) string {
+	marked := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		marked[p] = true
+	}
+
+	runes := []rune(line)
+	var b strings.Builder
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && marked[i] == marked[start] {
+			continue
+		}
+		segment := string(runes[start:i])
+		if marked[start] {
+			b.WriteString(highlight.Render(segment))
+		} else {
+			b.WriteString(base.Render(segment))
+		}
+		start = i
+	}
+	return b.String()
+}
+
+// truncateEnd cuts the end of s to fit in width columns, putting tail in
+// its place
+func truncateEnd(s string, width int, tail string) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	width -= lipgloss.Width(tail)
+	used := 0
+	for i, r := range s {
+		used += lipgloss.Width(string(r))
+		if used > width {
+			return s[:i] + tail
+		}
+	}
+	return s
+}
+
+// truncateStart cuts the start of s to fit in width columns, putting head
+// in its place
+func truncateStart(s string, width int, head string) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	width -= lipgloss.Width(head)
+	runes := []rune(s)
+	used := 0
+	for i := len(runes) - 1; i >= 0; i-- {
+		used += lipgloss.Width(string(runes[i]))
+		if used > width {
+			return head + string(runes[i+1:])
+		}
+	}
+	return s
+}
+
+// padRight pads s with spaces to width columns
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(0, width-lipgloss.Width(s)))
+}
+
 // renderPreview renders the preview pane
 func (m Model) renderPreview(width int) string {
 	height := m.height - 4
@@ -175,17 +325,38 @@ func (m Model) renderStatusBar() string {
 	}
 
 	leftInfo := fmt.Sprintf(" %d files | %s", len(m.files), utils.HumanizeSize(totalSize))
+	if m.filter != "" && m.mode != ModeSearch {
+		leftInfo = fmt.Sprintf(" /%s: %d of %d | %s", m.filter, len(m.files), len(m.allFiles), utils.HumanizeSize(totalSize))
+	}
 
-	// Center: status message
+	if m.hidden > 0 {
+		leftInfo += fmt.Sprintf(" | %d hidden", m.hidden)
+	}
+	if len(m.selected) > 0 {
+		leftInfo += fmt.Sprintf(" | %d selected (%s)", len(m.selected), utils.HumanizeSize(m.selectionSize()))
+	}
+	if m.mode == ModeVisual {
+		leftInfo = " -- VISUAL --" + leftInfo
+	}
+
+	// Center: confirmation prompt, job progress or status message
 	centerInfo := ""
-	if m.statusMsg != "" {
+	if m.mode == ModeConfirm {
+		centerInfo = " " + m.confirm.message + " (y/n) "
+	} else if m.mode == ModeCommand {
+		centerInfo = " " + m.renderCommandLine() + " "
+	} else if m.mode == ModeSearch {
+		centerInfo = fmt.Sprintf(" %s  %d/%d ", m.searchInput.View(), len(m.files), len(m.allFiles))
+	} else if j := m.activeJob(); j != nil {
+		centerInfo = " " + m.renderJobProgress(j) + " "
+	} else if m.statusMsg != "" {
 		centerInfo = " " + m.statusMsg + " "
 	} else if m.err != nil {
 		centerInfo = fmt.Sprintf(" Error: %v ", m.err)
 	}
 
-	// Right side: cursor position and preview status
-	rightInfo := ""
+	// Right side: sort order, cursor position and preview status
+	rightInfo := m.sortIndicator() + "  "
 	if len(m.files) > 0 {
 		previewStatus := ""
 		if m.previewEnabled {
@@ -195,7 +366,11 @@ func (m Model) renderStatusBar() string {
 		if m.syntaxHighlight {
 			syntaxStatus = "🎨 "
 		}
-		rightInfo = fmt.Sprintf("%s%s%d/%d ", syntaxStatus, previewStatus, m.cursor+1, len(m.files))
+		rightInfo += fmt.Sprintf("%s%s%d/%d ", syntaxStatus, previewStatus, m.cursor+1, len(m.files))
+	}
+	// Keys typed so far of a multi-key sequence
+	if len(m.pending) > 0 {
+		rightInfo = m.pending.String() + "…  " + rightInfo
 	}
 
 	// Build status bar
@@ -211,6 +386,24 @@ func (m Model) renderStatusBar() string {
 		Render(statusLine)
 }
 
+// renderJobProgress describes the running job for the status bar
+func (m Model) renderJobProgress(j *job) string {
+	progress := fmt.Sprintf("%s %d/%d", j.kind, j.items, len(j.sources))
+	if j.files > 0 {
+		progress += fmt.Sprintf(" · %d files", j.files)
+	}
+	if len(j.errs) > 0 {
+		progress += fmt.Sprintf(" · %d errors", len(j.errs))
+	}
+	if j.current != "" {
+		progress += " · " + filepath.Base(j.current)
+	}
+	if pending := len(m.jobs) - 1; pending > 0 {
+		progress += fmt.Sprintf(" (+%d queued)", pending)
+	}
+	return progress
+}
+
 // Helper functions
 func max(a, b int) int {
 	if a > b {
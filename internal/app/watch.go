@@ -0,0 +1,207 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/icichainz/sushi/internal/fs"
+	"github.com/icichainz/sushi/internal/ignore"
+	"github.com/icichainz/sushi/internal/watch"
+)
+
+// fsChangedMsg is sent when watched paths changed on disk, with the
+// entries that changed already scanned
+type fsChangedMsg struct {
+	entries  map[string]map[string]*fs.FileInfo // Changed entries by directory and name, nil if gone
+	paths    map[string]bool                    // Watched paths that changed themselves
+	overflow bool                               // Changes were lost, reload everything
+}
+
+// waitForChanges waits for the next batch of changes from the watcher
+func waitForChanges(w *watch.Watcher) tea.Cmd {
+	if w == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		changes, ok := <-w.C
+		if !ok {
+			return nil
+		}
+
+		msg := fsChangedMsg{
+			entries:  make(map[string]map[string]*fs.FileInfo, len(changes.Entries)),
+			paths:    changes.Paths,
+			overflow: changes.Overflow,
+		}
+		for dir, names := range changes.Entries {
+			scanned := make(map[string]*fs.FileInfo, len(names))
+			for name := range names {
+				path := filepath.Join(dir, name)
+				scanned[name] = nil
+				if info, err := os.Lstat(path); err == nil {
+					file := fs.NewFileInfo(path, info)
+					scanned[name] = &file
+				}
+			}
+			msg.entries[dir] = scanned
+		}
+		return msg
+	}
+}
+
+// watchShown watches the directories on screen and the previewed file,
+// leaving the watcher alone unless they changed
+func (m Model) watchShown() Model {
+	if m.watcher == nil {
+		return m
+	}
+	paths := []string{m.currentPath}
+	if m.previewEnabled {
+		paths = append(paths, m.preview.Path)
+	}
+	if m.otherPane() >= 0 {
+		paths = append(paths, m.otherPaneModel().currentPath)
+	}
+	if m.treeView {
+		paths = append(paths, m.tree.expandedUnder(m.currentPath)...)
+	}
+
+	slices.Sort(paths)
+	paths = slices.Compact(paths)
+	if !slices.Equal(paths, m.watched) {
+		m.watcher.Watch(paths...)
+		m.watched = paths
+	}
+	return m
+}
+
+// quit closes the watcher and quits. A running job would be stopped
+// partway, leaving files half copied or moved, so once confirmed the queue
+// is dropped and the running job waited for.
+func (m Model) quit() (Model, tea.Cmd) {
+	if j := m.activeJob(); j != nil {
+		if m.quitAfterJobs {
+			m.statusMsg = fmt.Sprintf("Quitting once %s finishes", strings.ToLower(j.kind.String()))
+			return m, nil
+		}
+		picked := m.picked
+		m.picked = nil // Only kept if confirmed
+		prompt := fmt.Sprintf("%s %s is running. Quit once it finishes?", j.kind, describePaths(j.sources))
+		return m.askConfirm(prompt, func(m Model) (Model, tea.Cmd) {
+			m.picked = picked
+			if len(m.jobs) > 1 {
+				m.jobs = m.jobs[:1]
+			}
+			m.quitAfterJobs = true
+			return m.quit()
+		})
+	}
+
+	if m.watcher != nil {
+		m.watcher.Close()
+	}
+	return m, tea.Quit
+}
+
+// applyChanges patches the listings on screen with the changes on disk,
+// keeping the cursor and marks on the same paths
+func (m Model) applyChanges(msg fsChangedMsg) (Model, tea.Cmd) {
+	cmds := []tea.Cmd{waitForChanges(m.watcher)}
+	if m.mode == ModeTrash {
+		return m, cmds[0] // The trash browser owns the listing
+	}
+	if msg.overflow {
+		return m, tea.Batch(cmds[0], m.refresh())
+	}
+
+	// Leave a directory removed under our feet for its closest parent
+	if msg.paths[m.currentPath] {
+		if _, err := os.Stat(m.currentPath); err != nil {
+			dir := m.currentPath
+			for ; dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+				if _, err := os.Stat(dir); err == nil {
+					break
+				}
+			}
+			m, cmd := m.enterDir(dir, "")
+			return m, tea.Batch(cmds[0], cmd)
+		}
+	}
+
+	for dir, changed := range msg.entries {
+		for name, file := range changed {
+			if slices.Contains(ignore.Files, name) {
+				m.ignores.Forget(dir)
+			}
+			if file == nil {
+				delete(m.selected, filepath.Join(dir, name))
+			}
+		}
+		if files, ok := m.tree.children[dir]; ok {
+			m.tree.children[dir] = patchFiles(files, changed)
+		}
+	}
+
+	if changed, ok := msg.entries[m.currentPath]; ok {
+		m = m.patchListing(changed)
+	} else if m.treeView {
+		m = m.applyFilter()
+	}
+	if other := m.otherPane(); other >= 0 {
+		if changed, ok := msg.entries[m.otherPaneModel().currentPath]; ok {
+			m = m.applyToOtherPane(func(m Model) Model {
+				return m.patchListing(changed)
+			})
+		}
+	}
+
+	// Reload the preview if what it shows changed
+	previewed := m.preview.Path
+	_, dirChanged := msg.entries[previewed]
+	if previewed != m.cursorPath() || msg.paths[previewed] || dirChanged ||
+		msg.entries[filepath.Dir(previewed)][filepath.Base(previewed)] != nil {
+		cmds = append(cmds, m.previewCursor())
+	}
+
+	if len(msg.paths) > 0 {
+		m.watched = nil // Watches of removed or replaced paths are gone
+	}
+	m = m.watchShown()
+	return m, tea.Batch(cmds...)
+}
+
+// patchListing applies the changed entries of the current directory. The
+// cursor stays on its entry, or in place if the entry is gone.
+func (m Model) patchListing(changed map[string]*fs.FileInfo) Model {
+	cursor, chosen := m.cursor, m.cursorPath()
+	m = m.setFiles(patchFiles(m.allFiles, changed))
+	m.listings.put(m.currentPath, m.allFiles)
+	if m.cursorPath() != chosen {
+		m.cursor = max(0, min(cursor, len(m.files)-1))
+	}
+	if m.mode == ModeVisual {
+		m = m.extendVisual()
+	}
+	return m
+}
+
+// patchFiles replaces the changed entries of a listing, dropping those
+// that are gone; the result is left for the caller to sort
+func patchFiles(files []fs.FileInfo, changed map[string]*fs.FileInfo) []fs.FileInfo {
+	out := make([]fs.FileInfo, 0, len(files)+len(changed))
+	for _, file := range files {
+		if _, ok := changed[file.Name]; !ok {
+			out = append(out, file)
+		}
+	}
+	for _, file := range changed {
+		if file != nil {
+			out = append(out, *file)
+		}
+	}
+	return out
+}
@@ -0,0 +1,168 @@
+package bookmarks
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/icichainz/sushi/internal/fs"
+)
+
+// Bookmark is a directory saved under a name and, optionally, a key
+type Bookmark struct {
+	Key  string // Letter or digit jumping to the bookmark, may be empty
+	Name string
+	Path string
+}
+
+// Missing reports whether the bookmarked directory no longer exists
+func (b Bookmark) Missing() bool {
+	info, err := os.Stat(b.Path)
+	return err != nil || !info.IsDir()
+}
+
+// Bookmarks is the ordered list of bookmarks, saved after every change.
+// The file holds one bookmark per line as key, name and path separated by
+// tabs.
+type Bookmarks struct {
+	path string
+	List []Bookmark
+}
+
+// Load reads the bookmarks stored at path, returning an empty list if the
+// file does not exist yet. Malformed lines are reported but the others are
+// still loaded, as are bookmarks reusing a key, without it.
+func Load(path string) (*Bookmarks, error) {
+	b := &Bookmarks{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return b, nil
+	}
+	if err != nil {
+		return b, err
+	}
+
+	var bad, reused []int
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 || fields[2] == "" || len([]rune(fields[0])) > 1 {
+			bad = append(bad, n)
+			continue
+		}
+		bm := Bookmark{Key: fields[0], Name: fields[1], Path: fields[2]}
+		if _, ok := b.Get(bm.Key); ok && bm.Key != "" {
+			reused = append(reused, n)
+			bm.Key = ""
+		}
+		b.List = append(b.List, bm)
+	}
+
+	var problems []string
+	if len(bad) > 0 {
+		problems = append(problems, fmt.Sprintf("malformed line(s) %v", bad))
+	}
+	if len(reused) > 0 {
+		problems = append(problems, fmt.Sprintf("key already used on line(s) %v", reused))
+	}
+	if len(problems) > 0 {
+		return b, fmt.Errorf("bookmarks %s: %s", path, strings.Join(problems, ", "))
+	}
+	return b, nil
+}
+
+// Get returns the bookmark bound to key
+func (b *Bookmarks) Get(key string) (Bookmark, bool) {
+	for _, bm := range b.List {
+		if bm.Key == key {
+			return bm, true
+		}
+	}
+	return Bookmark{}, false
+}
+
+// Set binds key to dir, moving the key's bookmark there if it exists
+func (b *Bookmarks) Set(key, dir string) error {
+	if err := checkField(dir); err != nil {
+		return err
+	}
+	for i, bm := range b.List {
+		if bm.Key == key {
+			b.List[i].Name = defaultName(dir)
+			b.List[i].Path = dir
+			return b.save()
+		}
+	}
+	b.List = append(b.List, Bookmark{Key: key, Name: defaultName(dir), Path: dir})
+	return b.save()
+}
+
+// Add appends a bookmark without a key, named after dir if name is empty
+func (b *Bookmarks) Add(name, dir string) error {
+	if name == "" {
+		name = defaultName(dir)
+	}
+	if err := checkField(name + dir); err != nil {
+		return err
+	}
+	b.List = append(b.List, Bookmark{Name: name, Path: dir})
+	return b.save()
+}
+
+// Rename changes the name of bookmark i
+func (b *Bookmarks) Rename(i int, name string) error {
+	if err := checkField(name); err != nil {
+		return err
+	}
+	b.List[i].Name = name
+	return b.save()
+}
+
+// Delete removes bookmark i
+func (b *Bookmarks) Delete(i int) error {
+	b.List = append(b.List[:i], b.List[i+1:]...)
+	return b.save()
+}
+
+// Move swaps bookmark i with the one at j
+func (b *Bookmarks) Move(i, j int) error {
+	b.List[i], b.List[j] = b.List[j], b.List[i]
+	return b.save()
+}
+
+// save writes the bookmarks atomically
+func (b *Bookmarks) save() error {
+	if b.path == "" {
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, bm := range b.List {
+		fmt.Fprintf(&buf, "%s\t%s\t%s\n", bm.Key, bm.Name, bm.Path)
+	}
+	return fs.WriteFileAtomic(b.path, buf.Bytes(), 0o600)
+}
+
+// checkField rejects text that would break the line format of the file
+func checkField(s string) error {
+	if strings.ContainsAny(s, "\t\n") {
+		return fmt.Errorf("bookmarks cannot contain tabs or newlines")
+	}
+	return nil
+}
+
+// defaultName names a bookmark after the last element of dir
+func defaultName(dir string) string {
+	name := filepath.Base(dir)
+	if name == string(filepath.Separator) || name == "." {
+		return dir
+	}
+	return name
+}
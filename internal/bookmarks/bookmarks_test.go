@@ -0,0 +1,146 @@
+package bookmarks
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Bookmark
+		wantErr string // Part of the error expected, if any
+	}{
+		{
+			name:    "empty",
+			content: "",
+		},
+		{
+			name:    "keys and no keys",
+			content: "a\tsrc\t/home/me/src\n\tdocs\t/home/me/docs\n\n",
+			want:    []Bookmark{{"a", "src", "/home/me/src"}, {"", "docs", "/home/me/docs"}},
+		},
+		{
+			name:    "spaces kept",
+			content: "1\tmy files \t/home/me/my files\n",
+			want:    []Bookmark{{"1", "my files ", "/home/me/my files"}},
+		},
+		{
+			name:    "malformed lines skipped",
+			content: "a\tsrc\t/src\nno tabs\nb\tx\t/x\textra\nc\tempty path\t\nab\tlong key\t/ab\nd\tdocs\t/docs\n",
+			want:    []Bookmark{{"a", "src", "/src"}, {"d", "docs", "/docs"}},
+			wantErr: "malformed line(s) [2 3 4 5]",
+		},
+		{
+			name:    "duplicate keys",
+			content: "a\tsrc\t/src\na\tdocs\t/docs\n\tother\t/other\n",
+			want:    []Bookmark{{"a", "src", "/src"}, {"", "docs", "/docs"}, {"", "other", "/other"}},
+			wantErr: "key already used on line(s) [2]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bookmarks")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			b, err := Load(path)
+			if (err != nil) != (tt.wantErr != "") || err != nil && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+			if !slices.Equal(b.List, tt.want) {
+				t.Errorf("got %q, want %q", b.List, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	b, err := Load(filepath.Join(t.TempDir(), "none"))
+	if err != nil || len(b.List) != 0 {
+		t.Fatalf("got %v, %v, want an empty list", b.List, err)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bookmarks")
+	b, _ := Load(path)
+	for _, err := range []error{
+		b.Set("a", "/home/me/src"),
+		b.Add("", "/"),
+		b.Add("music", "/home/me/Music library"),
+		b.Set("1", "/tmp"),
+		b.Set("a", "/home/me/docs"), // Moves a
+		b.Rename(1, "root"),
+		b.Move(1, 2),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []Bookmark{
+		{"a", "docs", "/home/me/docs"},
+		{"", "music", "/home/me/Music library"},
+		{"", "root", "/"},
+		{"1", "tmp", "/tmp"},
+	}
+	if !slices.Equal(b.List, want) {
+		t.Fatalf("got %q, want %q", b.List, want)
+	}
+
+	// Tabs and newlines would break the file, and are refused
+	for _, err := range []error{
+		b.Add("a\tb", "/x"),
+		b.Add("", "/x\ty"),
+		b.Set("b", "/x\ny"),
+		b.Rename(0, "two\nlines"),
+	} {
+		if err == nil {
+			t.Error("tab or newline accepted")
+		}
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(loaded.List, want) {
+		t.Fatalf("loaded %q, want %q", loaded.List, want)
+	}
+
+	if err := loaded.Delete(0); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, _ = Load(path); !slices.Equal(loaded.List, want[1:]) {
+		t.Fatalf("after delete loaded %q, want %q", loaded.List, want[1:])
+	}
+	if _, ok := loaded.Get("a"); ok {
+		t.Error("deleted key still bound")
+	}
+}
+
+func TestMissing(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	os.WriteFile(file, nil, 0o600)
+	link := filepath.Join(dir, "link")
+	os.Symlink(dir, link)
+	broken := filepath.Join(dir, "broken")
+	os.Symlink(filepath.Join(dir, "gone"), broken)
+
+	for path, want := range map[string]bool{
+		dir:                           false,
+		link:                          false,
+		file:                          true,
+		broken:                        true,
+		filepath.Join(dir, "nothing"): true,
+	} {
+		if got := (Bookmark{Path: path}).Missing(); got != want {
+			t.Errorf("Missing(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/icichainz/sushi/internal/fs"
+	"github.com/icichainz/sushi/internal/ui/components"
+	"github.com/icichainz/sushi/internal/utils"
+)
+
+// Config holds the user's settings. Anything missing from the config file
+// keeps its default value.
+type Config struct {
+	Preview    Preview    `toml:"preview"`
+	Sort       Sort       `toml:"sort"`
+	Hidden     Hidden     `toml:"hidden"`
+	Finder     Finder     `toml:"finder"`
+	Navigation Navigation `toml:"navigation"`
+	Styles     Styles     `toml:"styles"`
+	Openers    []Opener   `toml:"opener"`
+
+	// Key sequences mapped to command lines, on top of the default bindings
+	Keys map[string]string `toml:"keys"`
+}
+
+// Preview configures the preview pane
+type Preview struct {
+	Enabled         bool   `toml:"enabled"`
+	ListWidth       int    `toml:"list_width"` // Percentage of the width given to the file list
+	SyntaxHighlight bool   `toml:"syntax_highlight"`
+	SyntaxTheme     string `toml:"syntax_theme"`
+	MaxLines        int    `toml:"max_lines"`
+	MaxSize         int64  `toml:"max_size"` // Larger files are not previewed, in bytes
+}
+
+// Sort is the order of directories that haven't been given their own
+type Sort struct {
+	By        string `toml:"by"` // One of fs.SortKeys
+	Reverse   bool   `toml:"reverse"`
+	DirsFirst bool   `toml:"dirs_first"`
+}
+
+// Hidden configures the entries left out of listings until shown
+type Hidden struct {
+	Show      bool     `toml:"show"`      // Start with hidden entries shown
+	Patterns  []string `toml:"patterns"`  // Globs matched against names, besides dotfiles
+	GitIgnore bool     `toml:"gitignore"` // Also hide what .gitignore and .ignore files ignore
+}
+
+// Finder limits the walk of the recursive file finder
+type Finder struct {
+	MaxDepth int      `toml:"max_depth"` // Directory levels below the current one, 0 for unlimited
+	Ignore   []string `toml:"ignore"`    // Globs matched against names, not descended into
+}
+
+// Navigation configures how directories are entered
+type Navigation struct {
+	Physical bool `toml:"physical"` // Resolve symlinks in the paths entered, like cd -P
+}
+
+// WalkOptions converts the settings to walk limits
+func (f Finder) WalkOptions() fs.WalkOptions {
+	return fs.WalkOptions{MaxDepth: f.MaxDepth, Ignore: f.Ignore}
+}
+
+// Order converts the settings to a sort order, once validated
+func (s Sort) Order() fs.SortOrder {
+	return fs.SortOrder{By: fs.SortKey(s.By), Reverse: s.Reverse, DirsFirst: s.DirsFirst}
+}
+
+// Path returns where the config file is read from
+func Path() string {
+	return filepath.Join(utils.ConfigDir(), "config.toml")
+}
+
+// Default returns the built-in configuration
+func Default() Config {
+	preview := components.DefaultPreviewConfig()
+	walk := fs.DefaultWalkOptions()
+	return Config{
+		Preview: Preview{
+			Enabled:         true,
+			ListWidth:       50,
+			SyntaxHighlight: preview.SyntaxHighlight,
+			SyntaxTheme:     preview.SyntaxTheme,
+			MaxLines:        preview.MaxLines,
+			MaxSize:         preview.MaxPreviewSize,
+		},
+		Sort: Sort{
+			By:        string(fs.SortName),
+			DirsFirst: true,
+		},
+		Hidden: Hidden{
+			Patterns: []string{},
+		},
+		Finder: Finder{
+			MaxDepth: walk.MaxDepth,
+			Ignore:   walk.Ignore,
+		},
+		Styles:  DefaultStyles(),
+		Openers: DefaultOpeners(),
+	}
+}
+
+// Load reads the config file at path on top of the defaults. A missing
+// file is not an error. Arrays replace the defaults rather than extend
+// them. Unknown keys and invalid values are reported together so they can
+// all be fixed at once.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	// Decoding into the default openers would fill the user's with the
+	// fields they leave out
+	cfg.Openers = nil
+	meta, err := toml.DecodeFile(path, &cfg)
+	if errors.Is(err, os.ErrNotExist) {
+		return Default(), nil
+	}
+	if err != nil {
+		return Default(), fmt.Errorf("%s: %w", path, err)
+	}
+	if !meta.IsDefined("opener") {
+		cfg.Openers = DefaultOpeners()
+	}
+
+	var problems []string
+	for _, key := range meta.Undecoded() {
+		problems = append(problems, fmt.Sprintf("unknown key %s", key))
+	}
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return Default(), fmt.Errorf("%s:\n  %s", path, strings.Join(problems, "\n  "))
+	}
+	return cfg, nil
+}
+
+// validate lists every invalid value
+func (c Config) validate() []string {
+	var problems []string
+	p := c.Preview
+	if p.ListWidth < 10 || p.ListWidth > 90 {
+		problems = append(problems, fmt.Sprintf("preview.list_width: %d is not between 10 and 90", p.ListWidth))
+	}
+	if !components.HasSyntaxTheme(p.SyntaxTheme) {
+		problems = append(problems, fmt.Sprintf("preview.syntax_theme: unknown theme %q", p.SyntaxTheme))
+	}
+	if p.MaxLines <= 0 {
+		problems = append(problems, fmt.Sprintf("preview.max_lines: %d must be positive", p.MaxLines))
+	}
+	if p.MaxSize <= 0 {
+		problems = append(problems, fmt.Sprintf("preview.max_size: %d must be positive", p.MaxSize))
+	}
+
+	if _, err := fs.ParseSortKey(c.Sort.By); err != nil {
+		problems = append(problems, fmt.Sprintf("sort.by: %v", err))
+	}
+
+	for _, pattern := range c.Hidden.Patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			problems = append(problems, fmt.Sprintf("hidden.patterns: bad pattern %q", pattern))
+		}
+	}
+
+	if c.Finder.MaxDepth < 0 {
+		problems = append(problems, fmt.Sprintf("finder.max_depth: %d must not be negative", c.Finder.MaxDepth))
+	}
+	for _, pattern := range c.Finder.Ignore {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			problems = append(problems, fmt.Sprintf("finder.ignore: bad pattern %q", pattern))
+		}
+	}
+
+	problems = append(problems, c.Styles.validate()...)
+
+	for i, opener := range c.Openers {
+		if opener.Name == "" {
+			problems = append(problems, fmt.Sprintf("opener[%d]: missing name", i))
+		}
+		if strings.TrimSpace(opener.Command) == "" {
+			problems = append(problems, fmt.Sprintf("opener[%d]: missing command", i))
+		}
+		if len(opener.Match) == 0 {
+			problems = append(problems, fmt.Sprintf("opener[%d]: missing match patterns", i))
+		}
+		for _, pattern := range opener.Match {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				problems = append(problems, fmt.Sprintf("opener[%d]: bad pattern %q", i, pattern))
+			}
+		}
+	}
+	return problems
+}
+
+// Write encodes the configuration as TOML
+func (c Config) Write(w io.Writer) error {
+	fmt.Fprintf(w, "# sushi configuration, read from %s\n", Path())
+	fmt.Fprint(w, "# Arrays, [[opener]] included, replace the defaults rather than extend them\n\n")
+	return toml.NewEncoder(w).Encode(c)
+}
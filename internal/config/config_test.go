@@ -0,0 +1,154 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes content to a config file and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadMissing(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "none.toml"))
+	if err != nil || !reflect.DeepEqual(cfg, Default()) {
+		t.Fatalf("got %+v, %v, want the defaults", cfg, err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    func(*Config) // Changes expected from the defaults
+	}{
+		{
+			name:    "empty",
+			content: "",
+			want:    func(*Config) {},
+		},
+		{
+			name:    "other settings kept",
+			content: "[preview]\nmax_lines = 10\n",
+			want:    func(c *Config) { c.Preview.MaxLines = 10 },
+		},
+		{
+			name:    "patterns replaced",
+			content: "[hidden]\npatterns = [\"*.o\"]\n[finder]\nignore = [\"target\"]\n",
+			want: func(c *Config) {
+				c.Hidden.Patterns = []string{"*.o"}
+				c.Finder.Ignore = []string{"target"}
+			},
+		},
+		{
+			name:    "patterns emptied",
+			content: "[finder]\nignore = []\n",
+			want:    func(c *Config) { c.Finder.Ignore = []string{} },
+		},
+		{
+			name:    "openers replaced",
+			content: "[[opener]]\nname = \"editor\"\ncommand = \"vi\"\nmatch = [\"text/*\"]\nterminal = true\n",
+			want: func(c *Config) {
+				c.Openers = []Opener{{Name: "editor", Command: "vi", Match: []string{"text/*"}, Terminal: true}}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := Default()
+			tt.want(&want)
+			got, err := Load(writeConfig(t, tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string // Problems reported, all of them at once
+	}{
+		{
+			name:    "syntax",
+			content: "[preview\n",
+			want:    []string{"to end table name"},
+		},
+		{
+			name:    "wrong type",
+			content: "[preview]\nmax_lines = \"many\"\n",
+			want:    []string{"max_lines"},
+		},
+		{
+			name:    "unknown keys",
+			content: "[preview]\nlist_wdth = 60\n[colors]\nfg = \"1\"\n",
+			want:    []string{"unknown key preview.list_wdth", "unknown key colors"},
+		},
+		{
+			name: "bad values",
+			content: "[preview]\nlist_width = 5\nsyntax_theme = \"nope\"\nmax_lines = 0\nmax_size = -1\n" +
+				"[sort]\nby = \"color\"\n[hidden]\npatterns = [\"[\"]\n[finder]\nmax_depth = -1\nignore = [\"[\"]\n",
+			want: []string{
+				"preview.list_width: 5", "preview.syntax_theme", "preview.max_lines: 0", "preview.max_size: -1",
+				"sort.by", "hidden.patterns", "finder.max_depth: -1", "finder.ignore",
+			},
+		},
+		{
+			name:    "bad style",
+			content: "[styles.file]\nfg = \"300\"\n",
+			want:    []string{"styles.file"},
+		},
+		{
+			name:    "incomplete opener",
+			content: "[[opener]]\nname = \"viewer\"\ncommand = \"feh\"\n\n[[opener]]\nmatch = [\"[\"]\n",
+			want: []string{
+				"opener[0]: missing match patterns",
+				"opener[1]: missing name", "opener[1]: missing command", "opener[1]: bad pattern",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(writeConfig(t, tt.content))
+			if err == nil {
+				t.Fatal("no error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("%q not reported in:\n%v", want, err)
+				}
+			}
+			if !reflect.DeepEqual(cfg, Default()) {
+				t.Errorf("got %+v along with the error, want the defaults", cfg)
+			}
+		})
+	}
+}
+
+func TestWriteLoadsBack(t *testing.T) {
+	var b strings.Builder
+	if err := Default().Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(writeConfig(t, b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("dumped defaults load as %+v", cfg)
+	}
+}
@@ -0,0 +1,39 @@
+package config
+
+import "runtime"
+
+// Opener is a rule choosing a program for matching files
+type Opener struct {
+	Name     string   `toml:"name"`     // Shown in the open with menu
+	Command  string   `toml:"command"`  // Program and arguments, {} is replaced by the file or appended
+	Match    []string `toml:"match"`    // Extensions (.png), MIME types (image/*) or name globs (Makefile)
+	Terminal bool     `toml:"terminal"` // Runs in the terminal with sushi suspended, otherwise detached
+}
+
+// DefaultOpeners returns the built-in rules, most preferred first. Rules
+// whose program is not installed are skipped when opening.
+func DefaultOpeners() []Opener {
+	text := []string{"text/*", "application/json", "application/xml", "application/javascript",
+		"application/x-sh", "application/toml", "application/yaml", "inode/x-empty"}
+
+	return []Opener{
+		{Name: "editor", Command: "$EDITOR", Match: text, Terminal: true},
+		{Name: "pager", Command: "less", Match: text, Terminal: true},
+		{Name: "image viewer", Command: "imv", Match: []string{"image/*"}},
+		{Name: "image viewer", Command: "feh", Match: []string{"image/*"}},
+		{Name: "media player", Command: "mpv", Match: []string{"video/*", "audio/*"}},
+		{Name: "document viewer", Command: "zathura", Match: []string{"application/pdf", "application/epub+zip"}},
+		{Name: "system default", Command: systemOpener(), Match: []string{"*"}},
+	}
+}
+
+// systemOpener returns the platform's "open with default app" command
+func systemOpener() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "open"
+	case "windows":
+		return "rundll32 url.dll,FileProtocolHandler"
+	}
+	return "xdg-open"
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/icichainz/sushi/internal/ui"
+)
+
+// Style overrides the colors and attributes of one UI element. Colors are
+// ANSI numbers (0-255) or hex values (#ff8800); an empty color is unset.
+type Style struct {
+	Foreground string `toml:"fg,omitempty"`
+	Background string `toml:"bg,omitempty"`
+	Bold       bool   `toml:"bold"`
+	Italic     bool   `toml:"italic"`
+	Underline  bool   `toml:"underline"`
+}
+
+// Styles configures the UI elements that can be restyled
+type Styles struct {
+	Header       Style `toml:"header"`
+	File         Style `toml:"file"`
+	SelectedFile Style `toml:"selected_file"`
+	StatusBar    Style `toml:"status_bar"`
+	EmptyDir     Style `toml:"empty_dir"`
+	Match        Style `toml:"match"`
+	Marked       Style `toml:"marked"`
+	Symlink      Style `toml:"symlink"`
+	BrokenLink   Style `toml:"broken_link"`
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// DefaultStyles describes ui.DefaultStyles
+func DefaultStyles() Styles {
+	d := ui.DefaultStyles()
+	return Styles{
+		Header:       styleOf(d.Header),
+		File:         styleOf(d.File),
+		SelectedFile: styleOf(d.SelectedFile),
+		StatusBar:    styleOf(d.StatusBar),
+		EmptyDir:     styleOf(d.EmptyDir),
+		Match:        styleOf(d.Match),
+		Marked:       styleOf(d.Marked),
+		Symlink:      styleOf(d.Symlink),
+		BrokenLink:   styleOf(d.BrokenLink),
+	}
+}
+
+// Apply sets the configured colors and attributes on the UI styles
+func (s Styles) Apply(styles ui.Styles) ui.Styles {
+	styles.Header = s.Header.apply(styles.Header)
+	styles.File = s.File.apply(styles.File)
+	styles.SelectedFile = s.SelectedFile.apply(styles.SelectedFile)
+	styles.StatusBar = s.StatusBar.apply(styles.StatusBar)
+	styles.EmptyDir = s.EmptyDir.apply(styles.EmptyDir)
+	styles.Match = s.Match.apply(styles.Match)
+	styles.Marked = s.Marked.apply(styles.Marked)
+	styles.Symlink = s.Symlink.apply(styles.Symlink)
+	styles.BrokenLink = s.BrokenLink.apply(styles.BrokenLink)
+	return styles
+}
+
+// validate lists every invalid color
+func (s Styles) validate() []string {
+	var problems []string
+	check := func(name string, style Style) {
+		for _, color := range []struct{ key, value string }{{"fg", style.Foreground}, {"bg", style.Background}} {
+			if !validColor(color.value) {
+				problems = append(problems, fmt.Sprintf("styles.%s.%s: bad color %q", name, color.key, color.value))
+			}
+		}
+	}
+	check("header", s.Header)
+	check("file", s.File)
+	check("selected_file", s.SelectedFile)
+	check("status_bar", s.StatusBar)
+	check("empty_dir", s.EmptyDir)
+	check("match", s.Match)
+	check("marked", s.Marked)
+	check("symlink", s.Symlink)
+	check("broken_link", s.BrokenLink)
+	return problems
+}
+
+// apply sets the style's colors and attributes on base, keeping its layout
+func (s Style) apply(base lipgloss.Style) lipgloss.Style {
+	base = base.UnsetForeground().UnsetBackground().
+		Bold(s.Bold).
+		Italic(s.Italic).
+		Underline(s.Underline)
+	if s.Foreground != "" {
+		base = base.Foreground(lipgloss.Color(s.Foreground))
+	}
+	if s.Background != "" {
+		base = base.Background(lipgloss.Color(s.Background))
+	}
+	return base
+}
+
+// styleOf extracts the configurable parts of a lipgloss style
+func styleOf(style lipgloss.Style) Style {
+	return Style{
+		Foreground: colorOf(style.GetForeground()),
+		Background: colorOf(style.GetBackground()),
+		Bold:       style.GetBold(),
+		Italic:     style.GetItalic(),
+		Underline:  style.GetUnderline(),
+	}
+}
+
+func colorOf(color lipgloss.TerminalColor) string {
+	if c, ok := color.(lipgloss.Color); ok {
+		return string(c)
+	}
+	return ""
+}
+
+// validColor accepts an empty string, an ANSI color number or a hex color
+func validColor(color string) bool {
+	if color == "" || hexColor.MatchString(color) {
+		return true
+	}
+	n, err := strconv.Atoi(color)
+	return err == nil && n >= 0 && n <= 255
+}
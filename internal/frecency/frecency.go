@@ -0,0 +1,252 @@
+package frecency
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/icichainz/sushi/internal/fs"
+)
+
+// maxTotalRank bounds the sum of all ranks. Past it every rank is scaled
+// down and the least used directories are forgotten, like zoxide does.
+const maxTotalRank = 10000
+
+// Entry is a visited directory and how much it was used
+type Entry struct {
+	Path       string
+	Rank       float64 // Grows by one per visit
+	LastAccess time.Time
+}
+
+// Score weighs the rank of an entry by how recently it was used
+func (e Entry) Score(now time.Time) float64 {
+	age := now.Sub(e.LastAccess)
+	switch {
+	case age < time.Hour:
+		return e.Rank * 4
+	case age < 24*time.Hour:
+		return e.Rank * 2
+	case age < 7*24*time.Hour:
+		return e.Rank / 2
+	}
+	return e.Rank / 4
+}
+
+// Database keeps the visited directories, saved after every change in
+// the format of z: one "path|rank|unix time" line per directory. It is safe
+// for concurrent use.
+type Database struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]*Entry
+}
+
+// Load reads the database stored at path, returning an empty one if the
+// file does not exist yet
+func Load(path string) (*Database, error) {
+	d := &Database{path: path, entries: make(map[string]*Entry)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return d, nil
+	}
+	if err != nil {
+		return d, err
+	}
+
+	entries, err := parseZ(data)
+	for _, e := range entries {
+		d.entries[e.Path] = &e
+	}
+	if err != nil {
+		return d, fmt.Errorf("frecency database %s: %w", path, err)
+	}
+	return d, nil
+}
+
+// Visit records a visit of dir
+func (d *Database) Visit(dir string) error {
+	if strings.ContainsRune(dir, '\n') {
+		return nil // Can't be stored in the file
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	e, ok := d.entries[dir]
+	if !ok {
+		e = &Entry{Path: dir}
+		d.entries[dir] = e
+	}
+	e.Rank++
+	e.LastAccess = time.Now()
+	d.age()
+	return d.save()
+}
+
+// Remove forgets dir
+func (d *Database) Remove(dir string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.entries[dir]; !ok {
+		return nil
+	}
+	delete(d.entries, dir)
+	return d.save()
+}
+
+// Import merges entries from another database, adding up the ranks of
+// directories known to both. It returns the number of new directories.
+func (d *Database) Import(entries []Entry) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	added := 0
+	for _, imported := range entries {
+		if !filepath.IsAbs(imported.Path) || imported.Rank <= 0 {
+			continue
+		}
+		e, ok := d.entries[imported.Path]
+		if !ok {
+			e = &Entry{Path: imported.Path}
+			d.entries[imported.Path] = e
+			added++
+		}
+		e.Rank += imported.Rank
+		if imported.LastAccess.After(e.LastAccess) {
+			e.LastAccess = imported.LastAccess
+		}
+	}
+	d.age()
+	return added, d.save()
+}
+
+// Query returns the directories matching all keywords, best score first.
+// Keywords must appear in order in the path, ignoring case, and the last
+// one within the final path element. Without keywords every directory
+// matches.
+func (d *Database) Query(keywords []string) []Entry {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	var matches []Entry
+	for _, e := range d.entries {
+		if Matches(e.Path, keywords) {
+			matches = append(matches, *e)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		si, sj := matches[i].Score(now), matches[j].Score(now)
+		if si != sj {
+			return si > sj
+		}
+		return matches[i].Path < matches[j].Path
+	})
+	return matches
+}
+
+// Matches reports whether path matches the keywords as Query does
+func Matches(path string, keywords []string) bool {
+	rest := strings.ToLower(path)
+	for _, k := range keywords {
+		k = strings.ToLower(k)
+		i := strings.Index(rest, k)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(k):]
+	}
+	if len(keywords) == 0 {
+		return true
+	}
+	last := strings.ToLower(keywords[len(keywords)-1])
+	return strings.Contains(strings.ToLower(filepath.Base(path)), last)
+}
+
+// age scales the ranks down once their total grows past maxTotalRank,
+// dropping the entries that fall below one; the caller must hold d.mu
+func (d *Database) age() {
+	total := 0.0
+	for _, e := range d.entries {
+		total += e.Rank
+	}
+	if total <= maxTotalRank {
+		return
+	}
+
+	factor := 0.9 * maxTotalRank / total
+	for path, e := range d.entries {
+		e.Rank *= factor
+		if e.Rank < 1 {
+			delete(d.entries, path)
+		}
+	}
+}
+
+// save writes the database atomically; the caller must hold d.mu
+func (d *Database) save() error {
+	if d.path == "" {
+		return nil
+	}
+
+	paths := make([]string, 0, len(d.entries))
+	for path := range d.entries {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	for _, path := range paths {
+		e := d.entries[path]
+		fmt.Fprintf(&buf, "%s|%s|%d\n", e.Path, strconv.FormatFloat(e.Rank, 'f', -1, 64), e.LastAccess.Unix())
+	}
+	return fs.WriteFileAtomic(d.path, buf.Bytes(), 0o600)
+}
+
+// parseZ reads "path|rank|unix time" lines, as written by z and by this
+// package. The path may itself contain "|". Malformed lines are skipped and
+// reported.
+func parseZ(data []byte) ([]Entry, error) {
+	var entries []Entry
+	var bad []int
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		rest, stamp, ok1 := cutLast(line, "|")
+		path, rank, ok2 := cutLast(rest, "|")
+		r, err1 := strconv.ParseFloat(rank, 64)
+		t, err2 := strconv.ParseInt(stamp, 10, 64)
+		if !ok1 || !ok2 || err1 != nil || err2 != nil || path == "" {
+			bad = append(bad, n)
+			continue
+		}
+		entries = append(entries, Entry{Path: path, Rank: r, LastAccess: time.Unix(t, 0)})
+	}
+	if len(bad) > 0 {
+		return entries, fmt.Errorf("malformed line(s) %v", bad)
+	}
+	return entries, nil
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,146 @@
+package frecency
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseZ(t *testing.T) {
+	entries, err := parseZ([]byte("/home/me|12.5|1700000000\n\n/a|b|c|2|1700000001\nbroken\n/x|nan?|1\n"))
+	want := []Entry{
+		{Path: "/home/me", Rank: 12.5, LastAccess: time.Unix(1700000000, 0)},
+		{Path: "/a|b|c", Rank: 2, LastAccess: time.Unix(1700000001, 0)},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("got %v, want %v", entries, want)
+	}
+	if err == nil || err.Error() != "malformed line(s) [4 5]" {
+		t.Errorf("got error %v, want lines 4 and 5 reported", err)
+	}
+}
+
+// zoxideDB encodes entries in the zoxide format
+func zoxideDB(version uint32, entries ...Entry) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, version)
+	binary.Write(&b, binary.LittleEndian, uint64(len(entries)))
+	for _, e := range entries {
+		binary.Write(&b, binary.LittleEndian, uint64(len(e.Path)))
+		b.WriteString(e.Path)
+		binary.Write(&b, binary.LittleEndian, e.Rank)
+		binary.Write(&b, binary.LittleEndian, uint64(e.LastAccess.Unix()))
+	}
+	return b.Bytes()
+}
+
+func TestParseZoxide(t *testing.T) {
+	want := []Entry{
+		{Path: "/home/me/src", Rank: 3.5, LastAccess: time.Unix(1700000000, 0)},
+		{Path: "/tmp", Rank: 1, LastAccess: time.Unix(1700000100, 0)},
+	}
+	data := zoxideDB(zoxideVersion, want...)
+
+	entries, err := parseZoxide(data, time.Time{})
+	if err != nil || !reflect.DeepEqual(entries, want) {
+		t.Errorf("got %v, %v, want %v", entries, err, want)
+	}
+
+	// Entries read before the data ends are kept
+	entries, err = parseZoxide(data[:len(data)-4], time.Time{})
+	if err == nil || !reflect.DeepEqual(entries, want[:1]) {
+		t.Errorf("truncated: got %v, %v, want %v and an error", entries, err, want[:1])
+	}
+
+	if _, err := parseZoxide(zoxideDB(zoxideVersion+1, want...), time.Time{}); err == nil {
+		t.Error("unknown version accepted")
+	}
+	if _, err := parseZoxide([]byte{3}, time.Time{}); err == nil {
+		t.Error("truncated header accepted")
+	}
+}
+
+func TestParseAutojump(t *testing.T) {
+	mod := time.Unix(1700000000, 0)
+	entries, err := parseAutojump([]byte("10.5\t/home/me\n\n2\t/path\twith tab\nnope\n"), mod)
+	want := []Entry{
+		{Path: "/home/me", Rank: 10.5, LastAccess: mod},
+		{Path: "/path\twith tab", Rank: 2, LastAccess: mod},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("got %v, want %v", entries, want)
+	}
+	if err == nil {
+		t.Error("malformed line not reported")
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		path     string
+		keywords []string
+		want     bool
+	}{
+		{"/home/me/src/sushi", nil, true},
+		{"/home/me/src/sushi", []string{"sushi"}, true},
+		{"/home/me/src/sushi", []string{"SU"}, true},
+		{"/home/me/src/sushi", []string{"src", "sus"}, true},
+		{"/home/me/src/sushi", []string{"sus", "src"}, false}, // Out of order
+		{"/home/me/src/sushi", []string{"src"}, false},        // Not in the last element
+		{"/home/me/src/sushi", []string{"me", "me"}, false},
+		{"/home/me/src/sushi", []string{"home", "sushi"}, true},
+	}
+	for _, tt := range tests {
+		if got := Matches(tt.path, tt.keywords); got != tt.want {
+			t.Errorf("Matches(%s, %q) = %v, want %v", tt.path, tt.keywords, got, tt.want)
+		}
+	}
+}
+
+func TestDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "frecency")
+	d, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := time.Now().Add(-30 * 24 * time.Hour).Truncate(time.Second)
+	added, err := d.Import([]Entry{
+		{Path: "/src/old", Rank: 20, LastAccess: old},
+		{Path: "/src/recent", Rank: 1, LastAccess: old},
+		{Path: "relative", Rank: 5},
+		{Path: "/src/zero", Rank: 0},
+	})
+	if err != nil || added != 2 {
+		t.Fatalf("imported %d, %v, want 2 new", added, err)
+	}
+	d.Visit("/src/recent")
+	d.Visit("/src/recent")
+
+	// Recent visits outweigh an old rank: 3*4 against 20/4
+	got := d.Query([]string{"src"})
+	if len(got) != 0 {
+		t.Errorf("got %v, want no match outside the last element", got)
+	}
+	got = d.Query(nil)
+	if len(got) != 2 || got[0].Path != "/src/recent" || got[0].Rank != 3 {
+		t.Fatalf("got %v, want /src/recent ranked 3 first", got)
+	}
+
+	// Everything is saved
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again := reloaded.Query(nil); len(again) != 2 || again[1].Path != "/src/old" || !again[1].LastAccess.Equal(old) {
+		t.Errorf("reloaded %v, want %v", again, got)
+	}
+
+	d.Remove("/src/old")
+	if got := d.Query([]string{"old"}); len(got) != 0 {
+		t.Errorf("got %v after removing it", got)
+	}
+}
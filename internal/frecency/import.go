@@ -0,0 +1,158 @@
+package frecency
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/icichainz/sushi/internal/utils"
+)
+
+// Source is another directory jumper whose database can be imported
+type Source struct {
+	Name        string
+	DefaultPath func() string
+	parse       func(data []byte, modTime time.Time) ([]Entry, error)
+}
+
+// Sources lists the databases Read understands
+var Sources = []Source{
+	{Name: "zoxide", DefaultPath: zoxidePath, parse: parseZoxide},
+	{Name: "autojump", DefaultPath: autojumpPath, parse: parseAutojump},
+	{Name: "z", DefaultPath: zPath, parse: func(data []byte, _ time.Time) ([]Entry, error) {
+		return parseZ(data)
+	}},
+}
+
+// Read reads the database of the named source at path, or at its usual
+// location if path is empty
+func Read(name, path string) ([]Entry, string, error) {
+	for _, src := range Sources {
+		if src.Name != name {
+			continue
+		}
+		if path == "" {
+			path = src.DefaultPath()
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, path, err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, path, err
+		}
+		entries, err := src.parse(data, info.ModTime())
+		if err != nil {
+			err = fmt.Errorf("%s database %s: %w", name, path, err)
+		}
+		return entries, path, err
+	}
+	return nil, path, fmt.Errorf("unknown database %q", name)
+}
+
+// zoxidePath is where zoxide keeps its database
+func zoxidePath() string {
+	if dir := os.Getenv("_ZO_DATA_DIR"); dir != "" {
+		return filepath.Join(dir, "db.zo")
+	}
+	return filepath.Join(utils.DataHome(), "zoxide", "db.zo")
+}
+
+// autojumpPath is where autojump keeps its database
+func autojumpPath() string {
+	return filepath.Join(utils.DataHome(), "autojump", "autojump.txt")
+}
+
+// zPath is where z keeps its database
+func zPath() string {
+	if path := os.Getenv("_Z_DATA"); path != "" {
+		return path
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".z")
+}
+
+// zoxideVersion is the version of the zoxide database format understood
+const zoxideVersion = 3
+
+// parseZoxide decodes a zoxide database: a little endian u32 version, then
+// a u64 count of entries, each a u64 length prefixed path, an f64 rank and
+// a u64 last access time in seconds
+func parseZoxide(data []byte, _ time.Time) ([]Entry, error) {
+	r := bytes.NewReader(data)
+
+	var version uint32
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return nil, errors.New("truncated header")
+	}
+	if version != zoxideVersion {
+		return nil, fmt.Errorf("unsupported format version %d", version)
+	}
+
+	var count uint64
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return nil, errors.New("truncated header")
+	}
+
+	var entries []Entry
+	for i := uint64(0); i < count; i++ {
+		var n uint64
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil || n > uint64(r.Len()) {
+			return entries, errors.New("truncated entry")
+		}
+		path := make([]byte, n)
+		r.Read(path)
+
+		var fields struct {
+			Rank         float64
+			LastAccessed uint64
+		}
+		if err := binary.Read(r, binary.LittleEndian, &fields); err != nil {
+			return entries, errors.New("truncated entry")
+		}
+		if math.IsNaN(fields.Rank) || fields.LastAccessed > math.MaxInt64 {
+			continue
+		}
+		entries = append(entries, Entry{
+			Path:       string(path),
+			Rank:       fields.Rank,
+			LastAccess: time.Unix(int64(fields.LastAccessed), 0),
+		})
+	}
+	return entries, nil
+}
+
+// parseAutojump reads "weight<tab>path" lines. autojump keeps no times, so
+// every entry is dated from the file's modification.
+func parseAutojump(data []byte, modTime time.Time) ([]Entry, error) {
+	var entries []Entry
+	var bad []int
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		weight, path, ok := strings.Cut(line, "\t")
+		w, err := strconv.ParseFloat(weight, 64)
+		if !ok || err != nil || path == "" {
+			bad = append(bad, n)
+			continue
+		}
+		entries = append(entries, Entry{Path: path, Rank: w, LastAccess: modTime})
+	}
+	if len(bad) > 0 {
+		return entries, fmt.Errorf("malformed line(s) %v", bad)
+	}
+	return entries, nil
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package fs
+
+// deviceOf is not supported on this platform, so every path is treated as
+// living on the home filesystem
+func deviceOf(path string) (uint64, bool) {
+	return 0, false
+}
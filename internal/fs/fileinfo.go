@@ -1,23 +1,36 @@
 package fs
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 )
 
-// FileInfo represents metadata about a file or directory
+// FileInfo represents metadata about a file or directory. A symlink is
+// described by its target, but for Perms and the Link fields.
 type FileInfo struct {
 	Name    string
 	Path    string
 	Size    int64
 	ModTime time.Time
-	IsDir   bool
-	Perms   os.FileMode
+	IsDir   bool        // Also set for symlinks to directories
+	Perms   os.FileMode // Mode of the entry itself, with ModeSymlink for links
+
+	// Symlinks only: where the link points, what it leads to and whether
+	// that is missing or loops back
+	IsLink     bool
+	LinkTarget string      // As stored in the link
+	TargetMode os.FileMode // Mode of the final target, zero if broken
+	Broken     bool        // The target can't be reached
+	Loop       bool        // The link leads to itself or to one of its parents
 }
 
-// NewFileInfo creates a FileInfo from os.FileInfo
+// NewFileInfo creates a FileInfo from os.FileInfo, following symlinks
 func NewFileInfo(path string, info os.FileInfo) FileInfo {
-	return FileInfo{
+	f := FileInfo{
 		Name:    info.Name(),
 		Path:    path,
 		Size:    info.Size(),
@@ -25,4 +38,55 @@ func NewFileInfo(path string, info os.FileInfo) FileInfo {
 		IsDir:   info.IsDir(),
 		Perms:   info.Mode(),
 	}
-}
\ No newline at end of file
+	if info.Mode()&os.ModeSymlink != 0 {
+		f.resolveLink()
+	}
+	return f
+}
+
+// resolveLink fills in where the symlink f points
+func (f *FileInfo) resolveLink() {
+	f.IsLink = true
+	f.LinkTarget, _ = os.Readlink(f.Path)
+
+	target, err := os.Stat(f.Path)
+	if err != nil {
+		f.Broken = true
+		f.Loop = errors.Is(err, syscall.ELOOP)
+		return
+	}
+	f.TargetMode = target.Mode()
+	f.IsDir = target.IsDir()
+	f.Size = target.Size()
+	f.ModTime = target.ModTime()
+	f.Loop = f.IsDir && leadsToParent(f.Path)
+}
+
+// leadsToParent reports whether the directory symlink at path resolves to
+// the directory containing it or one of its parents, so that following it
+// recursively never ends
+func leadsToParent(path string) bool {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	parent, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(target, parent)
+	return err == nil && (rel == "." || !strings.HasPrefix(rel, ".."))
+}
+
+// Description names what a symlink leads to, for display
+func (f FileInfo) Description() string {
+	switch {
+	case f.Loop && f.Broken:
+		return "symlink loop"
+	case f.Broken:
+		return "broken symlink to " + f.LinkTarget
+	case f.Loop:
+		return "symlink to a parent directory"
+	}
+	return "symlink to " + f.LinkTarget
+}
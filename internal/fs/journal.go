@@ -0,0 +1,420 @@
+package fs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// OpKind identifies a journaled file operation
+type OpKind string
+
+const (
+	OpRename OpKind = "rename"
+	OpMove   OpKind = "move"
+	OpCopy   OpKind = "copy"
+	OpMkdir  OpKind = "mkdir"
+	OpTrash  OpKind = "trash"
+)
+
+// maxJournalEntries caps how many operations are kept on disk
+const maxJournalEntries = 200
+
+// ErrNothingToUndo is returned by Undo when the journal is exhausted
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// ErrNothingToRedo is returned by Redo when no undone operation is left
+var ErrNothingToRedo = errors.New("nothing to redo")
+
+// Stamp identifies the state of a file so later changes can be detected
+type Stamp struct {
+	IsDir   bool      `json:"is_dir"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Step is a single path change within an operation. From is empty for
+// operations that create something, and Stamp describes the entry at
+// whichever end of the step currently holds it.
+type Step struct {
+	From  string `json:"from,omitempty"`
+	To    string `json:"to"`
+	Stamp Stamp  `json:"stamp"`
+}
+
+// Operation is one user action, possibly touching several files
+type Operation struct {
+	Kind  OpKind    `json:"kind"`
+	Steps []Step    `json:"steps"`
+	Time  time.Time `json:"time"`
+}
+
+// String describes the operation for status messages
+func (op Operation) String() string {
+	if len(op.Steps) == 1 {
+		return fmt.Sprintf("%s of %s", op.Kind, filepath.Base(op.Steps[0].To))
+	}
+	return fmt.Sprintf("%s of %d items", op.Kind, len(op.Steps))
+}
+
+// Journal records file operations so they can be undone and redone. It is
+// persisted after every change and is safe for concurrent use.
+type Journal struct {
+	mu   sync.Mutex
+	path string
+
+	Entries  []Operation `json:"entries"`
+	Position int         `json:"position"` // Entries before Position are applied
+}
+
+// LoadJournal reads the journal stored at path, returning an empty journal
+// if the file does not exist yet
+func LoadJournal(path string) (*Journal, error) {
+	j := &Journal{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return j, nil
+	}
+	if err != nil {
+		return j, err
+	}
+	if err := json.Unmarshal(data, j); err != nil {
+		return &Journal{path: path}, fmt.Errorf("journal %s: %w", path, err)
+	}
+	j.Position = max(0, min(j.Position, len(j.Entries)))
+	return j, nil
+}
+
+// NewStep creates a step whose stamp is taken from the entry now at to
+func NewStep(from, to string) Step {
+	step := Step{From: from, To: to}
+	step.Stamp, _ = stampOf(to)
+	return step
+}
+
+// Record appends an operation, discarding anything that could be redone
+func (j *Journal) Record(op Operation) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if op.Time.IsZero() {
+		op.Time = time.Now()
+	}
+	j.Entries = append(j.Entries[:j.Position], op)
+	if len(j.Entries) > maxJournalEntries {
+		j.Entries = j.Entries[len(j.Entries)-maxJournalEntries:]
+	}
+	j.Position = len(j.Entries)
+	return j.save()
+}
+
+// Undo reverts the most recent applied operation. Nothing is touched if
+// any of its files changed since the operation was performed, and the
+// steps already reverted are re-applied if one fails.
+func (j *Journal) Undo() (Operation, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.Position == 0 {
+		return Operation{}, ErrNothingToUndo
+	}
+	op := &j.Entries[j.Position-1]
+
+	sim := overlay{}
+	for i := len(op.Steps) - 1; i >= 0; i-- {
+		if err := sim.checkUndo(op.Kind, op.Steps[i]); err != nil {
+			return *op, fmt.Errorf("cannot undo %s: %w", op, err)
+		}
+	}
+
+	// Revert in reverse order so nested changes unwind correctly
+	for i := len(op.Steps) - 1; i >= 0; i-- {
+		if err := undoStep(op.Kind, &op.Steps[i]); err != nil {
+			err = reapply(op.Kind, op.Steps[i+1:], fmt.Errorf("undo %s failed: %w", op, err))
+			return *op, errors.Join(err, j.save())
+		}
+	}
+
+	j.Position--
+	return *op, j.save()
+}
+
+// Redo re-applies the most recently undone operation, reverting the steps
+// already re-applied if one fails
+func (j *Journal) Redo() (Operation, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.Position == len(j.Entries) {
+		return Operation{}, ErrNothingToRedo
+	}
+	op := &j.Entries[j.Position]
+
+	sim := overlay{}
+	for _, step := range op.Steps {
+		if err := sim.checkRedo(op.Kind, step); err != nil {
+			return *op, fmt.Errorf("cannot redo %s: %w", op, err)
+		}
+	}
+
+	for i := range op.Steps {
+		if err := redoStep(op.Kind, &op.Steps[i]); err != nil {
+			err = unapply(op.Kind, op.Steps[:i], fmt.Errorf("redo %s failed: %w", op, err))
+			return *op, errors.Join(err, j.save())
+		}
+	}
+
+	j.Position++
+	return *op, j.save()
+}
+
+// reapply redoes steps reverted before an undo failed with err, returning
+// err with any rollback failure attached
+func reapply(kind OpKind, steps []Step, err error) error {
+	for i := range steps {
+		if rbErr := redoStep(kind, &steps[i]); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+	}
+	return err
+}
+
+// unapply reverts steps re-applied before a redo failed with err, returning
+// err with any rollback failure attached
+func unapply(kind OpKind, steps []Step, err error) error {
+	for i := len(steps) - 1; i >= 0; i-- {
+		if rbErr := undoStep(kind, &steps[i]); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+	}
+	return err
+}
+
+// save writes the journal atomically; the caller must hold j.mu
+func (j *Journal) save() error {
+	if j.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(j.path, data, 0o600)
+}
+
+// overlay tracks which paths earlier steps of an operation would create or
+// vacate, so steps that depend on each other (like a rename cycle going
+// through a temporary name) can be checked before anything is touched
+type overlay map[string]bool
+
+// moved records that a step moved an entry from one path to another
+func (o overlay) moved(from, to string) {
+	o[from] = false
+	o[to] = true
+}
+
+// checkStamp is like the package-level checkStamp, trusting entries
+// placed or vacated by earlier steps
+func (o overlay) checkStamp(path string, want Stamp) error {
+	if exists, ok := o[path]; ok {
+		if !exists {
+			return fmt.Errorf("%s no longer exists", path)
+		}
+		return nil
+	}
+	return checkStamp(path, want)
+}
+
+// checkFree is like the package-level checkFree, counting paths placed or
+// vacated by earlier steps
+func (o overlay) checkFree(path string) error {
+	if exists, ok := o[path]; ok {
+		if exists {
+			return fmt.Errorf("%s already exists", path)
+		}
+		return nil
+	}
+	return checkFree(path)
+}
+
+// checkUndo verifies that a step can be reverted safely
+func (o overlay) checkUndo(kind OpKind, step Step) error {
+	if err := o.checkStamp(step.To, step.Stamp); err != nil {
+		return err
+	}
+
+	switch kind {
+	case OpRename, OpMove, OpTrash:
+		if err := o.checkFree(step.From); err != nil {
+			return err
+		}
+		o.moved(step.To, step.From)
+	case OpCopy:
+		o[step.To] = false
+	case OpMkdir:
+		entries, err := os.ReadDir(step.To)
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			return fmt.Errorf("%s is no longer empty", step.To)
+		}
+		o[step.To] = false
+	}
+	return nil
+}
+
+// checkRedo verifies that a step can be applied again safely
+func (o overlay) checkRedo(kind OpKind, step Step) error {
+	switch kind {
+	case OpRename, OpMove, OpTrash:
+		if err := o.checkStamp(step.From, step.Stamp); err != nil {
+			return err
+		}
+		if kind == OpTrash {
+			o[step.From] = false
+			return nil // The trash picks a free name itself
+		}
+		if err := o.checkFree(step.To); err != nil {
+			return err
+		}
+		o.moved(step.From, step.To)
+	case OpCopy:
+		if _, err := os.Lstat(step.From); err != nil {
+			return fmt.Errorf("%s no longer exists", step.From)
+		}
+		if err := o.checkFree(step.To); err != nil {
+			return err
+		}
+		o[step.To] = true
+	case OpMkdir:
+		if err := o.checkFree(step.To); err != nil {
+			return err
+		}
+		o[step.To] = true
+	}
+	return nil
+}
+
+// undoStep reverts a single step and updates its stamp
+func undoStep(kind OpKind, step *Step) error {
+	var err error
+	switch kind {
+	case OpRename, OpMove:
+		err = Move(step.To, step.From, nil)
+	case OpTrash:
+		err = RestoreTrashed(step.To, nil)
+	case OpCopy:
+		// Removing the copy goes through the trash so undo never loses data
+		_, err = Trash(step.To)
+	case OpMkdir:
+		err = os.Remove(step.To)
+	}
+	if err != nil {
+		return err
+	}
+
+	if step.From != "" && kind != OpCopy {
+		step.Stamp, _ = stampOf(step.From)
+	}
+	return nil
+}
+
+// redoStep re-applies a single step and updates its stamp
+func redoStep(kind OpKind, step *Step) error {
+	var err error
+	switch kind {
+	case OpRename, OpMove:
+		err = Move(step.From, step.To, nil)
+	case OpTrash:
+		step.To, err = Trash(step.From)
+	case OpCopy:
+		var failed []error
+		err = Copy(step.From, step.To, func(path string, err error) {
+			if err != nil {
+				failed = append(failed, err)
+			}
+		})
+		if len(failed) > 0 {
+			err = errors.Join(failed...)
+		}
+		if err != nil {
+			os.RemoveAll(step.To) // Only a partial copy of our own is there
+		}
+	case OpMkdir:
+		err = os.Mkdir(step.To, 0o755)
+	}
+	if err != nil {
+		return err
+	}
+
+	step.Stamp, _ = stampOf(step.To)
+	return nil
+}
+
+// checkStamp fails if the entry at path differs from the recorded stamp
+func checkStamp(path string, want Stamp) error {
+	got, err := stampOf(path)
+	if err != nil {
+		return fmt.Errorf("%s no longer exists", path)
+	}
+	if got.IsDir != want.IsDir {
+		return fmt.Errorf("%s has changed type", path)
+	}
+	if !got.IsDir && (got.Size != want.Size || !got.ModTime.Equal(want.ModTime)) {
+		return fmt.Errorf("%s has been modified", path)
+	}
+	return nil
+}
+
+// checkFree fails if something already exists at path
+func checkFree(path string) error {
+	if _, err := os.Lstat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	return nil
+}
+
+// stampOf captures the current state of path
+func stampOf(path string) (Stamp, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return Stamp{}, err
+	}
+	return Stamp{
+		IsDir:   info.IsDir(),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}, nil
+}
+
+// WriteFileAtomic writes data to a temporary file and renames it over path
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
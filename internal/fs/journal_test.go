@@ -0,0 +1,241 @@
+package fs
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// makeTree creates the entries of want under dir; names ending in a slash
+// are directories, the others files holding their value
+func makeTree(t *testing.T, dir string, want map[string]string) {
+	t.Helper()
+	for name, content := range want {
+		path := filepath.Join(dir, name)
+		if name[len(name)-1] == '/' {
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readTree lists the entries under dir in the form taken by makeTree
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	got := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if d.IsDir() {
+			got[filepath.ToSlash(rel)+"/"] = ""
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		got[filepath.ToSlash(rel)] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+// checkTree fails the test if dir doesn't hold exactly want
+func checkTree(t *testing.T, dir string, want map[string]string, when string) {
+	t.Helper()
+	if got := readTree(t, dir); !maps.Equal(got, want) {
+		t.Fatalf("%s: got %v, want %v", when, got, want)
+	}
+}
+
+// newTestJournal returns a journal kept in memory, with the trash used to
+// undo copies outside of dir
+func newTestJournal(t *testing.T) *Journal {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	return &Journal{}
+}
+
+func TestJournalUndoRedo(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  map[string]string
+		perform func(dir string) ([]Step, error)
+		kind    OpKind
+		after   map[string]string
+	}{
+		{
+			name:   "rename",
+			before: map[string]string{"a": "1"},
+			kind:   OpRename,
+			perform: func(dir string) ([]Step, error) {
+				return ApplyRenames(RenamePlan{Renames: []Rename{
+					{filepath.Join(dir, "a"), filepath.Join(dir, "b")},
+				}})
+			},
+			after: map[string]string{"b": "1"},
+		},
+		{
+			name:   "swap",
+			before: map[string]string{"a": "1", "b": "2"},
+			kind:   OpRename,
+			perform: func(dir string) ([]Step, error) {
+				return ApplyRenames(RenamePlan{Renames: []Rename{
+					{filepath.Join(dir, "a"), filepath.Join(dir, "b")},
+					{filepath.Join(dir, "b"), filepath.Join(dir, "a")},
+				}})
+			},
+			after: map[string]string{"a": "2", "b": "1"},
+		},
+		{
+			name:   "move",
+			before: map[string]string{"a": "1", "d/": ""},
+			kind:   OpMove,
+			perform: func(dir string) ([]Step, error) {
+				from, to := filepath.Join(dir, "a"), filepath.Join(dir, "d", "a")
+				err := Move(from, to, nil)
+				return []Step{NewStep(from, to)}, err
+			},
+			after: map[string]string{"d/": "", "d/a": "1"},
+		},
+		{
+			name:   "copy",
+			before: map[string]string{"d/": "", "d/a": "1"},
+			kind:   OpCopy,
+			perform: func(dir string) ([]Step, error) {
+				from, to := filepath.Join(dir, "d"), filepath.Join(dir, "e")
+				err := Copy(from, to, nil)
+				return []Step{NewStep(from, to)}, err
+			},
+			after: map[string]string{"d/": "", "d/a": "1", "e/": "", "e/a": "1"},
+		},
+		{
+			name:   "mkdir",
+			before: map[string]string{},
+			kind:   OpMkdir,
+			perform: func(dir string) ([]Step, error) {
+				path := filepath.Join(dir, "d")
+				err := os.Mkdir(path, 0o755)
+				return []Step{NewStep("", path)}, err
+			},
+			after: map[string]string{"d/": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := newTestJournal(t)
+			dir := t.TempDir()
+			makeTree(t, dir, tt.before)
+
+			steps, err := tt.perform(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := j.Record(Operation{Kind: tt.kind, Steps: steps}); err != nil {
+				t.Fatal(err)
+			}
+			checkTree(t, dir, tt.after, "performed")
+
+			if _, err := j.Undo(); err != nil {
+				t.Fatalf("undo: %v", err)
+			}
+			checkTree(t, dir, tt.before, "undone")
+			if _, err := j.Undo(); !errors.Is(err, ErrNothingToUndo) {
+				t.Fatalf("second undo: got %v, want %v", err, ErrNothingToUndo)
+			}
+
+			if _, err := j.Redo(); err != nil {
+				t.Fatalf("redo: %v", err)
+			}
+			checkTree(t, dir, tt.after, "redone")
+			if _, err := j.Redo(); !errors.Is(err, ErrNothingToRedo) {
+				t.Fatalf("second redo: got %v, want %v", err, ErrNothingToRedo)
+			}
+
+			if _, err := j.Undo(); err != nil {
+				t.Fatalf("undo after redo: %v", err)
+			}
+			checkTree(t, dir, tt.before, "undone again")
+		})
+	}
+}
+
+func TestJournalUndoRefusesChangedFiles(t *testing.T) {
+	j := newTestJournal(t)
+	dir := t.TempDir()
+	makeTree(t, dir, map[string]string{"a": "1"})
+
+	from, to := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	if err := os.Rename(from, to); err != nil {
+		t.Fatal(err)
+	}
+	j.Record(Operation{Kind: OpRename, Steps: []Step{NewStep(from, to)}})
+	makeTree(t, dir, map[string]string{"b": "changed"})
+
+	if _, err := j.Undo(); err == nil {
+		t.Fatal("undo of a modified file succeeded")
+	}
+	checkTree(t, dir, map[string]string{"b": "changed"}, "refused")
+	if j.Position != 1 {
+		t.Fatalf("position moved to %d", j.Position)
+	}
+}
+
+// TestJournalRollback checks that a step failing partway leaves the files
+// and the entry as they were, so it can be retried
+func TestJournalRollback(t *testing.T) {
+	j := newTestJournal(t)
+	dir := t.TempDir()
+	before := map[string]string{"a": "1", "s/": "", "s/b": "2", "x/": "", "y/": ""}
+	after := map[string]string{"x/": "", "x/a": "1", "s/": "", "y/": "", "y/b": "2"}
+	makeTree(t, dir, before)
+
+	path := func(name string) string { return filepath.Join(dir, name) }
+	var steps []Step
+	for _, r := range []Rename{{path("s/b"), path("y/b")}, {path("a"), path("x/a")}} {
+		if err := os.Rename(r.From, r.To); err != nil {
+			t.Fatal(err)
+		}
+		steps = append(steps, NewStep(r.From, r.To))
+	}
+	j.Record(Operation{Kind: OpMove, Steps: steps})
+	checkTree(t, dir, after, "performed")
+
+	// Undo moves x/a back first, then fails on y/b as s is gone
+	os.Remove(path("s"))
+	delete(after, "s/")
+	if _, err := j.Undo(); err == nil {
+		t.Fatal("undo into a missing directory succeeded")
+	}
+	checkTree(t, dir, after, "undo rolled back")
+	os.Mkdir(path("s"), 0o755)
+	if _, err := j.Undo(); err != nil {
+		t.Fatalf("undo retried: %v", err)
+	}
+	checkTree(t, dir, before, "undone")
+
+	// Redo moves s/b first, then fails on a as x is gone
+	os.Remove(path("x"))
+	delete(before, "x/")
+	if _, err := j.Redo(); err == nil {
+		t.Fatal("redo into a missing directory succeeded")
+	}
+	checkTree(t, dir, before, "redo rolled back")
+	os.Mkdir(path("x"), 0o755)
+	if _, err := j.Redo(); err != nil {
+		t.Fatalf("redo retried: %v", err)
+	}
+	checkTree(t, dir, map[string]string{"x/": "", "x/a": "1", "s/": "", "y/": "", "y/b": "2"}, "redone")
+}
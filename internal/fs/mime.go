@@ -0,0 +1,41 @@
+package fs
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MimeType guesses the MIME type of a file, without parameters such as the
+// charset. The extension is tried first, then the file's leading bytes.
+func MimeType(path string) string {
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		return stripParams(t)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "application/octet-stream"
+	}
+	if n == 0 {
+		return "text/plain"
+	}
+	return stripParams(http.DetectContentType(head[:n]))
+}
+
+func stripParams(t string) string {
+	if i := strings.IndexByte(t, ';'); i >= 0 {
+		t = t[:i]
+	}
+	return strings.TrimSpace(t)
+}
@@ -0,0 +1,228 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// FileCallback is called after each entry processed by an operation
+type FileCallback func(path string, err error)
+
+// ErrIncomplete is returned by operations that went on past entries they
+// failed on, each failure having been passed to the FileCallback
+var ErrIncomplete = errors.New("incomplete")
+
+// UniquePath returns a path for name inside dir that does not exist yet,
+// appending " (n)" before the extension when needed
+func UniquePath(dir, name string) string {
+	candidate := filepath.Join(dir, name)
+	if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+		return candidate
+	}
+
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; ; i++ {
+		candidate = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", base, i, ext))
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// Copy recursively copies src to dst, preserving permissions and symlinks.
+// Entries that can't be copied are skipped, and make it return
+// ErrIncomplete.
+func Copy(src, dst string, onFile FileCallback) error {
+	if isWithin(dst, src) {
+		return fmt.Errorf("cannot copy %s into itself", src)
+	}
+
+	// Directories are writable while their children are copied, and get
+	// their own permissions last, deepest first
+	type dirPerm struct {
+		src, dst string
+		perm     os.FileMode
+	}
+	var dirs []dirPerm
+
+	failed := 0
+	err := filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			failed++
+			notify(onFile, path, err)
+			return nil
+		}
+
+		rel, _ := filepath.Rel(src, path)
+		target := filepath.Join(dst, rel)
+
+		perm, err := copyEntry(path, target, d)
+		notify(onFile, path, err)
+		if err != nil {
+			failed++
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+		} else if d.IsDir() {
+			dirs = append(dirs, dirPerm{path, target, perm})
+		}
+		return nil
+	})
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i].dst, dirs[i].perm); err != nil {
+			failed++
+			notify(onFile, dirs[i].src, err)
+		}
+	}
+	if err == nil && failed > 0 {
+		err = fmt.Errorf("%w: %d entries not copied", ErrIncomplete, failed)
+	}
+	return err
+}
+
+// Move renames src to dst, falling back to copy and delete across devices
+func Move(src, dst string, onFile FileCallback) error {
+	if isWithin(dst, src) {
+		return fmt.Errorf("cannot move %s into itself", src)
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s already exists", dst)
+	}
+
+	err := os.Rename(src, dst)
+	if err == nil {
+		notify(onFile, src, nil)
+		return nil
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	// Cross-device move: copy everything, then remove the source only if
+	// the copy succeeded completely
+	err = Copy(src, dst, onFile)
+	if errors.Is(err, ErrIncomplete) {
+		return fmt.Errorf("copy of %s incomplete, source kept", src)
+	}
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// Delete permanently removes path and everything below it. Entries that
+// can't be removed are kept along with the directories holding them, and
+// make it return ErrIncomplete.
+func Delete(path string, onFile FileCallback) error {
+	failed, err := deleteTree(path, onFile)
+	if err == nil && failed > 0 {
+		err = fmt.Errorf("%w: %d entries not deleted", ErrIncomplete, failed)
+	}
+	return err
+}
+
+// deleteTree removes path and everything below it, returning how many
+// entries below it failed. Those are passed to onFile, but the directories
+// left non-empty because of them are not.
+func deleteTree(path string, onFile FileCallback) (int, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, err
+	}
+
+	failed := 0
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return 0, err
+		}
+		for _, entry := range entries {
+			child := filepath.Join(path, entry.Name())
+			n, err := deleteTree(child, onFile)
+			if err != nil {
+				notify(onFile, child, err)
+				n++
+			}
+			failed += n
+		}
+	}
+	if failed > 0 {
+		return failed, nil
+	}
+
+	if err := os.Remove(path); err != nil {
+		return 0, err
+	}
+	notify(onFile, path, nil)
+	return 0, nil
+}
+
+// copyEntry copies a single directory entry without descending into it,
+// returning its permissions. Directories are created writable by the
+// owner, for the caller to restore their permissions once filled.
+func copyEntry(src, dst string, d os.DirEntry) (os.FileMode, error) {
+	info, err := d.Info()
+	if err != nil {
+		return 0, err
+	}
+	perm := info.Mode().Perm()
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(src)
+		if err != nil {
+			return 0, err
+		}
+		return perm, os.Symlink(link, dst)
+
+	case info.IsDir():
+		return perm, os.MkdirAll(dst, 0o700|perm)
+
+	case info.Mode().IsRegular():
+		return perm, copyFile(src, dst, perm)
+	}
+
+	return 0, fmt.Errorf("unsupported file type: %s", src)
+}
+
+// copyFile copies the contents of a regular file
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// isWithin reports whether path is dir itself or located below it
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+func notify(onFile FileCallback, path string, err error) {
+	if onFile != nil {
+		onFile(path, err)
+	}
+}
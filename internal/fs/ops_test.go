@@ -0,0 +1,92 @@
+package fs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// collect returns a callback gathering the paths processed and those that
+// failed
+func collect() (FileCallback, *[]string, *[]string) {
+	var done, failed []string
+	return func(path string, err error) {
+		if err != nil {
+			failed = append(failed, path)
+		} else {
+			done = append(done, path)
+		}
+	}, &done, &failed
+}
+
+func TestCopyIncomplete(t *testing.T) {
+	dir := t.TempDir()
+	makeTree(t, dir, map[string]string{"src/a": "1", "src/b": "2", "dst/a": "taken"})
+
+	onFile, _, failed := collect()
+	err := Copy(filepath.Join(dir, "src"), filepath.Join(dir, "dst"), onFile)
+	if !errors.Is(err, ErrIncomplete) {
+		t.Fatalf("got %v, want %v", err, ErrIncomplete)
+	}
+	if len(*failed) != 1 || filepath.Base((*failed)[0]) != "a" {
+		t.Errorf("got failures %v, want src/a", *failed)
+	}
+	checkTree(t, dir, map[string]string{
+		"src/": "", "src/a": "1", "src/b": "2",
+		"dst/": "", "dst/a": "taken", "dst/b": "2",
+	}, "copied")
+}
+
+func TestDeleteIncomplete(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions don't stop root")
+	}
+	dir := t.TempDir()
+	makeTree(t, dir, map[string]string{"d/a/locked/file": "", "d/a/b": "", "d/c": ""})
+	locked := filepath.Join(dir, "d/a/locked")
+	os.Chmod(locked, 0o555)
+	defer os.Chmod(locked, 0o755)
+
+	onFile, _, failed := collect()
+	err := Delete(filepath.Join(dir, "d"), onFile)
+	if !errors.Is(err, ErrIncomplete) {
+		t.Fatalf("got %v, want %v", err, ErrIncomplete)
+	}
+	// Only the file is reported, not the directories left holding it
+	if len(*failed) != 1 || filepath.Base((*failed)[0]) != "file" {
+		t.Errorf("got failures %v, want only the locked file", *failed)
+	}
+	checkTree(t, dir, map[string]string{"d/": "", "d/a/": "", "d/a/locked/": "", "d/a/locked/file": ""}, "deleted")
+}
+
+func TestCopyReadOnlyDir(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions don't stop root")
+	}
+	dir := t.TempDir()
+	makeTree(t, dir, map[string]string{"src/ro/a": "1", "src/ro/sub/b": "2"})
+	for _, name := range []string{"src/ro/sub", "src/ro"} {
+		os.Chmod(filepath.Join(dir, name), 0o555)
+	}
+
+	dst := filepath.Join(dir, "dst")
+	err := Copy(filepath.Join(dir, "src"), dst, nil)
+	for _, root := range []string{"src", "dst"} {
+		defer os.Chmod(filepath.Join(dir, root, "ro"), 0o755)
+		defer os.Chmod(filepath.Join(dir, root, "ro/sub"), 0o755)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"ro", "ro/sub"} {
+		info, err := os.Stat(filepath.Join(dst, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o555 {
+			t.Errorf("%s copied with mode %o, want 555", name, perm)
+		}
+	}
+	checkTree(t, dst, map[string]string{"ro/": "", "ro/a": "1", "ro/sub/": "", "ro/sub/b": "2"}, "copied")
+}
@@ -0,0 +1,164 @@
+package fs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Rename is a single requested path change
+type Rename struct {
+	From string
+	To   string
+}
+
+// RenamePlan is a validated set of renames
+type RenamePlan struct {
+	Renames []Rename
+	Cycles  int // Number of cycles that need a temporary name
+}
+
+// PlanRenames validates a batch of renames: every target must be unique
+// and either free or vacated by another rename in the batch
+func PlanRenames(renames []Rename) (RenamePlan, error) {
+	sources := make(map[string]bool, len(renames))
+	for _, r := range renames {
+		sources[r.From] = true
+	}
+
+	targets := make(map[string]string, len(renames))
+	var problems []string
+	for _, r := range renames {
+		if r.To == "" || filepath.Base(r.To) == "." || filepath.Base(r.To) == ".." {
+			problems = append(problems, fmt.Sprintf("%s: empty name", filepath.Base(r.From)))
+			continue
+		}
+		if other, ok := targets[r.To]; ok {
+			problems = append(problems, fmt.Sprintf("%s and %s both renamed to %s",
+				filepath.Base(other), filepath.Base(r.From), filepath.Base(r.To)))
+			continue
+		}
+		targets[r.To] = r.From
+
+		if _, err := os.Lstat(r.To); err == nil && !sources[r.To] {
+			problems = append(problems, fmt.Sprintf("%s already exists", r.To))
+		}
+		if info, err := os.Stat(filepath.Dir(r.To)); err != nil || !info.IsDir() {
+			problems = append(problems, fmt.Sprintf("%s: no such directory", filepath.Dir(r.To)))
+		}
+	}
+	if len(problems) > 0 {
+		return RenamePlan{}, fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+
+	plan := RenamePlan{Renames: renames}
+	plan.Cycles = countCycles(renames)
+	return plan, nil
+}
+
+// ApplyRenames performs the plan, ordering chains so no file is overwritten
+// and breaking cycles through temporary names. If a rename fails, the ones
+// already done are rolled back. The steps performed are returned for the
+// journal.
+func ApplyRenames(plan RenamePlan) ([]Step, error) {
+	pending := make(map[string]string, len(plan.Renames))
+	for _, r := range plan.Renames {
+		pending[r.From] = r.To
+	}
+
+	var steps []Step
+	rename := func(from, to string) error {
+		// Targets were free when planning, but os.Rename would silently
+		// replace anything created there since
+		if _, err := os.Lstat(to); err == nil {
+			return fmt.Errorf("%s already exists", to)
+		}
+		if err := os.Rename(from, to); err != nil {
+			return err
+		}
+		steps = append(steps, NewStep(from, to))
+		return nil
+	}
+
+	for len(pending) > 0 {
+		progress := false
+		for _, from := range sortedKeys(pending) {
+			to := pending[from]
+			// Wait until whatever sits at the target has moved away
+			if _, occupied := pending[to]; occupied {
+				continue
+			}
+			if err := rename(from, to); err != nil {
+				return nil, rollback(steps, err)
+			}
+			delete(pending, from)
+			progress = true
+		}
+		if progress {
+			continue
+		}
+
+		// Only cycles are left: park one file under a temporary name
+		from := sortedKeys(pending)[0]
+		tmp := UniquePath(filepath.Dir(from), ".sushi-rename-"+filepath.Base(from))
+		if err := rename(from, tmp); err != nil {
+			return nil, rollback(steps, err)
+		}
+		pending[tmp] = pending[from]
+		delete(pending, from)
+	}
+
+	return steps, nil
+}
+
+// rollback reverts performed steps after err, returning err with any
+// rollback failure attached
+func rollback(steps []Step, err error) error {
+	for i := len(steps) - 1; i >= 0; i-- {
+		if rbErr := os.Rename(steps[i].To, steps[i].From); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+	}
+	return err
+}
+
+// countCycles counts the rename cycles such as a→b, b→a
+func countCycles(renames []Rename) int {
+	next := make(map[string]string, len(renames))
+	for _, r := range renames {
+		next[r.From] = r.To
+	}
+
+	cycles := 0
+	visited := make(map[string]bool, len(renames))
+	for _, start := range sortedKeys(next) {
+		path := start
+		seen := map[string]bool{}
+		for !visited[path] && !seen[path] {
+			seen[path] = true
+			to, ok := next[path]
+			if !ok {
+				break
+			}
+			path = to
+		}
+		if path == start && seen[start] && !visited[start] {
+			cycles++
+		}
+		for p := range seen {
+			visited[p] = true
+		}
+	}
+	return cycles
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
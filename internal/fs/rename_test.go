@@ -0,0 +1,126 @@
+package fs
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyRenames(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  map[string]string
+		renames [][2]string
+		cycles  int
+		created map[string]string // Created between planning and applying
+		after   map[string]string // Unchanged from before if nil
+		wantErr bool
+	}{
+		{
+			name:    "single",
+			before:  map[string]string{"a": "1"},
+			renames: [][2]string{{"a", "b"}},
+			after:   map[string]string{"b": "1"},
+		},
+		{
+			name:    "swap",
+			before:  map[string]string{"a": "1", "b": "2"},
+			renames: [][2]string{{"a", "b"}, {"b", "a"}},
+			cycles:  1,
+			after:   map[string]string{"a": "2", "b": "1"},
+		},
+		{
+			name:    "3-cycle",
+			before:  map[string]string{"a": "1", "b": "2", "c": "3"},
+			renames: [][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}},
+			cycles:  1,
+			after:   map[string]string{"a": "3", "b": "1", "c": "2"},
+		},
+		{
+			name:    "two swaps",
+			before:  map[string]string{"a": "1", "b": "2", "c": "3", "d": "4"},
+			renames: [][2]string{{"a", "b"}, {"b", "a"}, {"c", "d"}, {"d", "c"}},
+			cycles:  2,
+			after:   map[string]string{"a": "2", "b": "1", "c": "4", "d": "3"},
+		},
+		{
+			name:    "chain",
+			before:  map[string]string{"a": "1", "b": "2", "c": "3"},
+			renames: [][2]string{{"a", "b"}, {"b", "c"}, {"c", "d"}},
+			after:   map[string]string{"b": "1", "c": "2", "d": "3"},
+		},
+		{
+			name:    "duplicate target",
+			before:  map[string]string{"a": "1", "b": "2", "c": "3"},
+			renames: [][2]string{{"a", "b"}, {"b", "c"}, {"c", "b"}},
+			wantErr: true, // b is the target twice
+		},
+		{
+			name:    "into another directory",
+			before:  map[string]string{"a": "1", "d/": ""},
+			renames: [][2]string{{"a", "d/a"}},
+			after:   map[string]string{"d/": "", "d/a": "1"},
+		},
+		{
+			name:    "existing target",
+			before:  map[string]string{"a": "1", "b": "2"},
+			renames: [][2]string{{"a", "b"}},
+			wantErr: true,
+		},
+		{
+			name:    "missing directory",
+			before:  map[string]string{"a": "1"},
+			renames: [][2]string{{"a", "d/a"}},
+			wantErr: true,
+		},
+		{
+			name:    "collision after planning",
+			before:  map[string]string{"a": "1", "c": "3"},
+			renames: [][2]string{{"a", "b"}, {"c", "d"}}, // a is renamed, then rolled back
+			created: map[string]string{"d": "new"},
+			after:   map[string]string{"a": "1", "c": "3", "d": "new"},
+			wantErr: true,
+		},
+		{
+			name:    "collision in a cycle after planning",
+			before:  map[string]string{"a": "1", "b": "2", "x": "3"},
+			renames: [][2]string{{"a", "b"}, {"b", "a"}, {"x", "y"}},
+			cycles:  1,
+			created: map[string]string{"y": "new"},
+			after:   map[string]string{"a": "1", "b": "2", "x": "3", "y": "new"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			makeTree(t, dir, tt.before)
+
+			renames := make([]Rename, len(tt.renames))
+			for i, r := range tt.renames {
+				renames[i] = Rename{From: filepath.Join(dir, r[0]), To: filepath.Join(dir, r[1])}
+			}
+			plan, err := PlanRenames(renames)
+			if err == nil {
+				if plan.Cycles != tt.cycles {
+					t.Errorf("got %d cycles, want %d", plan.Cycles, tt.cycles)
+				}
+				makeTree(t, dir, tt.created)
+				var steps []Step
+				steps, err = ApplyRenames(plan)
+				if err == nil && len(steps) != len(renames)+plan.Cycles {
+					t.Errorf("got %d steps, want %d", len(steps), len(renames)+plan.Cycles)
+				}
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			want := tt.after
+			if want == nil {
+				want = tt.before
+			}
+			checkTree(t, dir, want, "renamed")
+		})
+	}
+}
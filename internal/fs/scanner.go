@@ -4,7 +4,6 @@ package fs
 import (
 	"os"
 	"path/filepath"
-	"sort"
 )
 
 // ScanDirectory scans a directory and returns a list of files
@@ -28,12 +27,7 @@ func ScanDirectory(path string) ([]FileInfo, error) {
 	}
 
 	// Sort: directories first, then by name
-	sort.Slice(files, func(i, j int) bool {
-		if files[i].IsDir != files[j].IsDir {
-			return files[i].IsDir
-		}
-		return files[i].Name < files[j].Name
-	})
+	SortFiles(files, DefaultSortOrder())
 
 	return files, nil
 }
\ No newline at end of file
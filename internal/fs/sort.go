@@ -0,0 +1,186 @@
+package fs
+
+import (
+	"cmp"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SortKey selects the order of a directory listing
+type SortKey string
+
+const (
+	SortName      SortKey = "name"    // Byte-wise, uppercase first
+	SortNatural   SortKey = "natural" // Numbers by value, file2 before file10
+	SortNoCase    SortKey = "nocase"  // Ignoring case
+	SortSize      SortKey = "size"
+	SortTime      SortKey = "time"
+	SortExtension SortKey = "extension"
+	SortType      SortKey = "type" // Kind of entry, then MIME type
+)
+
+// SortKeys lists the available sort keys
+var SortKeys = []SortKey{SortName, SortNatural, SortNoCase, SortSize, SortTime, SortExtension, SortType}
+
+// ParseSortKey validates a sort key name
+func ParseSortKey(name string) (SortKey, error) {
+	for _, key := range SortKeys {
+		if string(key) == name {
+			return key, nil
+		}
+	}
+	return "", fmt.Errorf("unknown sort key %q", name)
+}
+
+// SortOrder is how a listing is sorted
+type SortOrder struct {
+	By        SortKey
+	Reverse   bool
+	DirsFirst bool // List directories before files, whatever the key
+}
+
+// DefaultSortOrder lists directories first, then everything by name
+func DefaultSortOrder() SortOrder {
+	return SortOrder{By: SortName, DirsFirst: true}
+}
+
+// String describes the order as its key followed by "reverse" and "mixed"
+// when directories aren't listed first, as read by ParseSortOrder
+func (o SortOrder) String() string {
+	s := string(o.By)
+	if o.Reverse {
+		s += " reverse"
+	}
+	if !o.DirsFirst {
+		s += " mixed"
+	}
+	return s
+}
+
+// ParseSortOrder reads an order written by SortOrder.String
+func ParseSortOrder(s string) (SortOrder, error) {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return SortOrder{}, fmt.Errorf("empty sort order")
+	}
+	by, err := ParseSortKey(words[0])
+	if err != nil {
+		return SortOrder{}, err
+	}
+	order := SortOrder{By: by, DirsFirst: true}
+	for _, word := range words[1:] {
+		switch word {
+		case "reverse":
+			order.Reverse = true
+		case "mixed":
+			order.DirsFirst = false
+		default:
+			return SortOrder{}, fmt.Errorf("unknown sort flag %q", word)
+		}
+	}
+	return order, nil
+}
+
+// SortFiles orders files in place. Ties are broken by name so the order
+// is stable across reloads.
+func SortFiles(files []FileInfo, order SortOrder) {
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if order.DirsFirst && a.IsDir != b.IsDir {
+			return a.IsDir
+		}
+		if order.Reverse {
+			a, b = b, a
+		}
+		if c := compareBy(order.By, a, b); c != 0 {
+			return c < 0
+		}
+		return a.Name < b.Name
+	})
+}
+
+// compareBy compares two files on a sort key alone
+func compareBy(by SortKey, a, b FileInfo) int {
+	switch by {
+	case SortNatural:
+		return naturalCompare(a.Name, b.Name)
+	case SortNoCase:
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	case SortSize:
+		return cmp.Compare(a.Size, b.Size)
+	case SortTime:
+		return a.ModTime.Compare(b.ModTime)
+	case SortExtension:
+		return strings.Compare(extension(a), extension(b))
+	case SortType:
+		if c := cmp.Compare(typeRank(a), typeRank(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(mime.TypeByExtension(extension(a)), mime.TypeByExtension(extension(b)))
+	}
+	return 0
+}
+
+// extension returns the lowercase extension of a file, directories and
+// dotfiles having none
+func extension(f FileInfo) string {
+	if f.IsDir || strings.LastIndex(f.Name, ".") <= 0 {
+		return ""
+	}
+	return strings.ToLower(filepath.Ext(f.Name))
+}
+
+// typeRank orders the kinds of entries: directories, symlinks, special
+// files, executables and then regular files
+func typeRank(f FileInfo) int {
+	switch {
+	case f.IsDir:
+		return 0
+	case f.Perms&os.ModeSymlink != 0:
+		return 1
+	case !f.Perms.IsRegular():
+		return 2 // Devices, pipes and sockets
+	case f.Perms&0o111 != 0:
+		return 3
+	}
+	return 4
+}
+
+// naturalCompare compares names with runs of digits ordered by value, so
+// that file2 comes before file10
+func naturalCompare(a, b string) int {
+	for a != "" && b != "" {
+		da, db := digits(a), digits(b)
+		if da == 0 || db == 0 {
+			if a[0] != b[0] {
+				return cmp.Compare(a[0], b[0])
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		// Compare the numbers without their leading zeros, longest is largest
+		na, nb := strings.TrimLeft(a[:da], "0"), strings.TrimLeft(b[:db], "0")
+		if c := cmp.Compare(len(na), len(nb)); c != 0 {
+			return c
+		}
+		if c := strings.Compare(na, nb); c != 0 {
+			return c
+		}
+		a, b = a[da:], b[db:]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// digits returns the length of the run of ASCII digits starting s
+func digits(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,90 @@
+package fs
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNaturalCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"file2", "file10", -1},
+		{"file10", "file2", 1},
+		{"file10", "file10", 0},
+		{"file", "file1", -1},
+		{"a", "b", -1},
+		{"B", "a", -1}, // Byte-wise outside numbers, like the name order
+		{"file02", "file2", 0},
+		{"file002", "file10", -1},
+		{"v1.10.0", "v1.9.3", 1},
+		{"1", "a", -1},
+		{"x99", "x100", -1},
+		{"x99y", "x99z", -1},
+		{"img12b", "img12a", 1},
+		{"18446744073709551616", "18446744073709551615", 1}, // Beyond 64 bits
+		{"", "a", -1},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		if got := naturalCompare(tt.a, tt.b); got != tt.want {
+			t.Errorf("naturalCompare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSortFiles(t *testing.T) {
+	files := func(names ...string) []FileInfo {
+		out := make([]FileInfo, len(names))
+		for i, name := range names {
+			out[i] = FileInfo{Name: name, IsDir: name == "dir10" || name == "dir9"}
+		}
+		return out
+	}
+	names := func(files []FileInfo) []string {
+		out := make([]string, len(files))
+		for i, f := range files {
+			out[i] = f.Name
+		}
+		return out
+	}
+
+	tests := []struct {
+		order SortOrder
+		want  []string
+	}{
+		{SortOrder{By: SortName, DirsFirst: true}, []string{"dir10", "dir9", "File3", "file1", "file10", "file2"}},
+		{SortOrder{By: SortNatural, DirsFirst: true}, []string{"dir9", "dir10", "File3", "file1", "file2", "file10"}},
+		{SortOrder{By: SortNatural}, []string{"File3", "dir9", "dir10", "file1", "file2", "file10"}},
+		{SortOrder{By: SortNatural, Reverse: true, DirsFirst: true}, []string{"dir10", "dir9", "file10", "file2", "file1", "File3"}},
+		{SortOrder{By: SortNoCase, DirsFirst: true}, []string{"dir10", "dir9", "file1", "file10", "file2", "File3"}},
+	}
+	for _, tt := range tests {
+		got := files("file10", "dir10", "file2", "File3", "dir9", "file1")
+		SortFiles(got, tt.order)
+		if !slices.Equal(names(got), tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.order, names(got), tt.want)
+		}
+	}
+}
+
+func TestParseSortOrder(t *testing.T) {
+	for _, order := range []SortOrder{
+		DefaultSortOrder(),
+		{By: SortNatural, Reverse: true, DirsFirst: true},
+		{By: SortTime, Reverse: true},
+		{By: SortType},
+	} {
+		got, err := ParseSortOrder(order.String())
+		if err != nil || got != order {
+			t.Errorf("ParseSortOrder(%q) = %v, %v, want %v", order.String(), got, err, order)
+		}
+	}
+
+	for _, s := range []string{"", "bogus", "name sideways"} {
+		if _, err := ParseSortOrder(s); err == nil {
+			t.Errorf("ParseSortOrder(%q) succeeded", s)
+		}
+	}
+}
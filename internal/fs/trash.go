@@ -0,0 +1,344 @@
+package fs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/icichainz/sushi/internal/utils"
+)
+
+// Trash implements the freedesktop.org Trash specification:
+// https://specifications.freedesktop.org/trash-spec/latest/
+
+// trashInfoExt is the extension of the metadata files in info/
+const trashInfoExt = ".trashinfo"
+
+// trashDateFormat is the DeletionDate layout required by the spec
+const trashDateFormat = "2006-01-02T15:04:05"
+
+// TrashItem is a file or directory sitting in a trash directory
+type TrashItem struct {
+	FileInfo               // The trashed entry, with Path inside the trash
+	OriginalPath string    // Absolute path the entry was deleted from
+	DeletionDate time.Time // When the entry was trashed
+}
+
+// HomeTrash returns the user's home trash directory
+func HomeTrash() string {
+	return filepath.Join(utils.DataHome(), "Trash")
+}
+
+// Trash moves path into the appropriate trash directory and records its
+// original location, returning the path of the entry inside the trash
+func Trash(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Lstat(path); err != nil {
+		return "", err
+	}
+
+	trashDir, topDir := trashDirFor(path)
+	trashed, err := trashInto(path, trashDir, topDir)
+	if err != nil && trashDir != HomeTrash() {
+		// Fall back to the home trash, which may require copying the data
+		return trashInto(path, HomeTrash(), "")
+	}
+	return trashed, err
+}
+
+// ListTrash returns the entries of the home trash and every per-mount trash,
+// most recently deleted first
+func ListTrash() ([]TrashItem, error) {
+	items, err := listTrashDir(HomeTrash(), "")
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	uid := strconv.Itoa(os.Getuid())
+	for _, mount := range mountPoints() {
+		for _, dir := range []string{
+			filepath.Join(mount, ".Trash", uid),
+			filepath.Join(mount, ".Trash-"+uid),
+		} {
+			if dir == HomeTrash() {
+				continue
+			}
+			mountItems, err := listTrashDir(dir, mount)
+			if err == nil {
+				items = append(items, mountItems...)
+			}
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletionDate.After(items[j].DeletionDate)
+	})
+	return items, nil
+}
+
+// ReadTrashItem loads the metadata of an entry inside a trash directory
+func ReadTrashItem(trashedPath string) (TrashItem, error) {
+	trashDir := filepath.Dir(filepath.Dir(trashedPath))
+	return readTrashItem(trashDir, filepath.Base(trashedPath), trashTopDir(trashDir))
+}
+
+// RestoreTrashed moves a trashed entry back to its original location
+func RestoreTrashed(trashedPath string, onFile FileCallback) error {
+	item, err := ReadTrashItem(trashedPath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(item.OriginalPath), 0o755); err != nil {
+		return err
+	}
+	if err := Move(trashedPath, item.OriginalPath, onFile); err != nil {
+		return err
+	}
+	return os.Remove(trashInfoPath(trashedPath))
+}
+
+// PurgeTrashed permanently deletes a trashed entry and its metadata
+func PurgeTrashed(trashedPath string, onFile FileCallback) error {
+	if err := Delete(trashedPath, onFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	err := os.Remove(trashInfoPath(trashedPath))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// trashDirFor picks the trash directory for path: the home trash when path
+// lives on the same filesystem, otherwise a trash at the top of its mount
+func trashDirFor(path string) (trashDir, topDir string) {
+	home := HomeTrash()
+
+	dev, ok := deviceOf(filepath.Dir(path))
+	if !ok {
+		return home, ""
+	}
+	if homeDev, ok := deviceOf(existingParent(home)); !ok || homeDev == dev {
+		return home, ""
+	}
+
+	top := mountTop(filepath.Dir(path), dev)
+	uid := strconv.Itoa(os.Getuid())
+
+	// An administrator-provided $topdir/.Trash must be a sticky,
+	// non-symlinked directory to be used
+	shared := filepath.Join(top, ".Trash")
+	if info, err := os.Lstat(shared); err == nil && info.IsDir() && info.Mode()&os.ModeSticky != 0 {
+		return filepath.Join(shared, uid), top
+	}
+	return filepath.Join(top, ".Trash-"+uid), top
+}
+
+// trashInto moves path into trashDir, writing its .trashinfo first so
+// the chosen name is reserved
+func trashInto(path, trashDir, topDir string) (string, error) {
+	filesDir := filepath.Join(trashDir, "files")
+	infoDir := filepath.Join(trashDir, "info")
+	for _, dir := range []string{filesDir, infoDir} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return "", err
+		}
+	}
+
+	// Paths in per-mount trashes are stored relative to the mount
+	recorded := path
+	if topDir != "" {
+		if rel, err := filepath.Rel(topDir, path); err == nil {
+			recorded = rel
+		}
+	}
+	info := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: filepath.ToSlash(recorded)}).EscapedPath(),
+		time.Now().Format(trashDateFormat))
+
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; ; i++ {
+		infoPath := filepath.Join(infoDir, name+trashInfoExt)
+		f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			name = fmt.Sprintf("%s.%d%s", base, i, ext)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		_, err = f.WriteString(info)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+
+		// The spec allows files/ entries left without their info file
+		trashed := filepath.Join(filesDir, name)
+		if _, statErr := os.Lstat(trashed); err == nil && statErr == nil {
+			os.Remove(infoPath)
+			name = fmt.Sprintf("%s.%d%s", base, i, ext)
+			continue
+		}
+		if err == nil {
+			err = Move(path, trashed, nil)
+		}
+		if err != nil {
+			os.Remove(infoPath)
+			return "", err
+		}
+		return trashed, nil
+	}
+}
+
+// listTrashDir reads all entries of a single trash directory
+func listTrashDir(trashDir, topDir string) ([]TrashItem, error) {
+	entries, err := os.ReadDir(filepath.Join(trashDir, "info"))
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]TrashItem, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), trashInfoExt)
+		if !ok {
+			continue
+		}
+		item, err := readTrashItem(trashDir, name, topDir)
+		if err != nil {
+			continue // Skip orphaned or malformed entries
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// readTrashItem parses the .trashinfo of name and stats the trashed entry
+func readTrashItem(trashDir, name, topDir string) (TrashItem, error) {
+	trashedPath := filepath.Join(trashDir, "files", name)
+	info, err := os.Lstat(trashedPath)
+	if err != nil {
+		return TrashItem{}, err
+	}
+
+	f, err := os.Open(trashInfoPath(trashedPath))
+	if err != nil {
+		return TrashItem{}, err
+	}
+	defer f.Close()
+
+	item := TrashItem{FileInfo: NewFileInfo(trashedPath, info)}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Path":
+			original, err := url.PathUnescape(value)
+			if err != nil {
+				return TrashItem{}, err
+			}
+			original = filepath.FromSlash(original)
+			if !filepath.IsAbs(original) {
+				original = filepath.Join(topDir, original)
+			}
+			item.OriginalPath = original
+		case "DeletionDate":
+			item.DeletionDate, _ = time.ParseInLocation(trashDateFormat, value, time.Local)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return TrashItem{}, err
+	}
+	if item.OriginalPath == "" {
+		return TrashItem{}, fmt.Errorf("%s: missing Path", name)
+	}
+	return item, nil
+}
+
+// trashInfoPath returns the .trashinfo file belonging to a trashed entry
+func trashInfoPath(trashedPath string) string {
+	trashDir := filepath.Dir(filepath.Dir(trashedPath))
+	return filepath.Join(trashDir, "info", filepath.Base(trashedPath)+trashInfoExt)
+}
+
+// trashTopDir returns the mount a per-mount trash belongs to, or "" for the
+// home trash
+func trashTopDir(trashDir string) string {
+	if trashDir == HomeTrash() {
+		return ""
+	}
+	if strings.HasPrefix(filepath.Base(trashDir), ".Trash-") {
+		return filepath.Dir(trashDir)
+	}
+	return filepath.Dir(filepath.Dir(trashDir)) // $topdir/.Trash/$uid
+}
+
+// mountTop walks up from dir to the top directory of the filesystem dev
+func mountTop(dir string, dev uint64) string {
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		if parentDev, ok := deviceOf(parent); !ok || parentDev != dev {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// existingParent returns path or its closest ancestor that exists
+func existingParent(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// mountPoints returns the mounted filesystems listed by the kernel, if the
+// platform exposes them
+func mountPoints() []string {
+	f, err := os.Open("/proc/self/mounts")
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var mounts []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		// Spaces and other special characters are octal-escaped
+		mount, err := strconv.Unquote(`"` + fields[1] + `"`)
+		if err != nil {
+			mount = fields[1]
+		}
+		mounts = append(mounts, mount)
+	}
+	return mounts
+}
@@ -0,0 +1,116 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrashIntoNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		trash    map[string]string // Already in the trash directory
+		file     string
+		wantName string
+	}{
+		{"free", nil, "a.txt", "a.txt"},
+		{"info taken", map[string]string{"files/a.txt": "old", "info/a.txt.trashinfo": "old"}, "a.txt", "a.1.txt"},
+		{"leftover file", map[string]string{"files/a.txt": "old"}, "a.txt", "a.1.txt"},
+		{"leftover info", map[string]string{"info/a.txt.trashinfo": "old"}, "a.txt", "a.1.txt"},
+		{"several taken", map[string]string{"files/a": "", "info/a.1.trashinfo": "", "files/a.2": ""}, "a", "a.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, trashDir := t.TempDir(), t.TempDir()
+			makeTree(t, trashDir, tt.trash)
+			makeTree(t, dir, map[string]string{tt.file: "new"})
+
+			path := filepath.Join(dir, tt.file)
+			trashed, err := trashInto(path, trashDir, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := filepath.Base(trashed); got != tt.wantName {
+				t.Errorf("trashed as %s, want %s", got, tt.wantName)
+			}
+
+			item, err := ReadTrashItem(trashed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if item.OriginalPath != path {
+				t.Errorf("original path %s, want %s", item.OriginalPath, path)
+			}
+
+			// Only the chosen name gained an info file
+			want := map[string]string{}
+			for name, content := range tt.trash {
+				want[name] = content
+			}
+			want["files/"], want["info/"] = "", ""
+			want["files/"+tt.wantName] = "new"
+			infoPath := "info/" + tt.wantName + trashInfoExt
+			data, _ := os.ReadFile(filepath.Join(trashDir, infoPath))
+			want[infoPath] = string(data)
+			checkTree(t, trashDir, want, "trashed")
+		})
+	}
+}
+
+func TestReadTrashItem(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     string
+		topDir   string
+		original string
+		date     string
+		wantErr  bool
+	}{
+		{
+			name:     "escaped",
+			info:     "[Trash Info]\nPath=/home/me/a%20b/100%25.txt\nDeletionDate=2024-03-01T10:20:30\n",
+			original: "/home/me/a b/100%.txt",
+			date:     "2024-03-01T10:20:30",
+		},
+		{
+			name:     "relative to the mount",
+			info:     "[Trash Info]\nPath=photos/x.jpg\nDeletionDate=2024-03-01T10:20:30\n",
+			topDir:   "/mnt/usb",
+			original: "/mnt/usb/photos/x.jpg",
+			date:     "2024-03-01T10:20:30",
+		},
+		{
+			name:     "bad date",
+			info:     "[Trash Info]\nPath=/a\nDeletionDate=yesterday\n",
+			original: "/a",
+		},
+		{name: "missing path", info: "[Trash Info]\nDeletionDate=2024-03-01T10:20:30\n", wantErr: true},
+		{name: "bad escape", info: "[Trash Info]\nPath=/a%zz\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trashDir := t.TempDir()
+			makeTree(t, trashDir, map[string]string{"files/item": "", "info/item" + trashInfoExt: tt.info})
+
+			item, err := readTrashItem(trashDir, "item", tt.topDir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if item.OriginalPath != filepath.FromSlash(tt.original) {
+				t.Errorf("original path %s, want %s", item.OriginalPath, tt.original)
+			}
+			date := ""
+			if !item.DeletionDate.IsZero() {
+				date = item.DeletionDate.Format(trashDateFormat)
+			}
+			if date != tt.date {
+				t.Errorf("deletion date %q, want %q", date, tt.date)
+			}
+		})
+	}
+}
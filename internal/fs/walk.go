@@ -0,0 +1,102 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// WalkOptions limits a recursive walk
+type WalkOptions struct {
+	MaxDepth int      // Maximum directory depth below the root, 0 for unlimited
+	Ignore   []string // Glob patterns matched against entry names
+}
+
+// DefaultWalkOptions returns the limits used by the recursive finder
+func DefaultWalkOptions() WalkOptions {
+	return WalkOptions{
+		MaxDepth: 12,
+		Ignore:   []string{".git", ".hg", ".svn", "node_modules", "__pycache__"},
+	}
+}
+
+// walkBatchSize and walkBatchInterval bound how often batches are sent
+const (
+	walkBatchSize     = 1024
+	walkBatchInterval = 50 * time.Millisecond
+)
+
+// Walk streams the paths below root, relative to it, in batches on out until
+// the tree is exhausted or ctx is cancelled. Directories are reported with a
+// trailing separator. out is closed when the walk ends.
+func Walk(ctx context.Context, root string, opts WalkOptions, out chan<- []string) {
+	defer close(out)
+
+	batch := make([]string, 0, walkBatchSize)
+	lastSend := time.Now()
+	send := func() bool {
+		if len(batch) == 0 {
+			return true
+		}
+		select {
+		case out <- batch:
+		case <-ctx.Done():
+			return false
+		}
+		batch = make([]string, 0, walkBatchSize)
+		lastSend = time.Now()
+		return true
+	}
+
+	filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return filepath.SkipAll
+		}
+		if err != nil || path == root {
+			return nil // Skip unreadable entries, keep walking
+		}
+
+		if matchesAny(d.Name(), opts.Ignore) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+
+		if d.IsDir() {
+			batch = append(batch, rel+string(filepath.Separator))
+			depth := strings.Count(rel, string(filepath.Separator)) + 1
+			if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+				return filepath.SkipDir
+			}
+		} else {
+			batch = append(batch, rel)
+		}
+
+		if len(batch) >= walkBatchSize || time.Since(lastSend) >= walkBatchInterval {
+			if !send() {
+				return filepath.SkipAll
+			}
+		}
+		return nil
+	})
+
+	send()
+}
+
+// matchesAny reports whether name matches one of the glob patterns
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// walkAll collects every path reported by Walk
+func walkAll(t *testing.T, root string, opts WalkOptions) []string {
+	t.Helper()
+	out := make(chan []string)
+	go Walk(context.Background(), root, opts, out)
+
+	var paths []string
+	for batch := range out {
+		paths = append(paths, batch...)
+	}
+	for i, path := range paths {
+		paths[i] = filepath.ToSlash(path)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func TestWalk(t *testing.T) {
+	dir := t.TempDir()
+	makeTree(t, dir, map[string]string{
+		"a":                "",
+		"d/b":              "",
+		"d/e/c":            "",
+		"d/e/f/g":          "",
+		"node_modules/x":   "",
+		"d/node_modules/y": "",
+		"d/e/skip.log":     "",
+	})
+
+	tests := []struct {
+		name string
+		opts WalkOptions
+		want []string
+	}{
+		{
+			name: "unlimited",
+			opts: WalkOptions{},
+			want: []string{
+				"a", "d/", "d/b", "d/e/", "d/e/c", "d/e/f/", "d/e/f/g", "d/e/skip.log",
+				"d/node_modules/", "d/node_modules/y", "node_modules/", "node_modules/x",
+			},
+		},
+		{
+			name: "depth 1",
+			opts: WalkOptions{MaxDepth: 1},
+			want: []string{"a", "d/", "node_modules/"},
+		},
+		{
+			name: "depth 2",
+			opts: WalkOptions{MaxDepth: 2},
+			want: []string{"a", "d/", "d/b", "d/e/", "d/node_modules/", "node_modules/", "node_modules/x"},
+		},
+		{
+			name: "ignored at any depth",
+			opts: WalkOptions{Ignore: []string{"node_modules", "*.log"}},
+			want: []string{"a", "d/", "d/b", "d/e/", "d/e/c", "d/e/f/", "d/e/f/g"},
+		},
+		{
+			name: "depth and ignore",
+			opts: WalkOptions{MaxDepth: 2, Ignore: []string{"e"}},
+			want: []string{"a", "d/", "d/b", "d/node_modules/", "node_modules/", "node_modules/x"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := walkAll(t, dir, tt.opts); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalkCancel(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{}
+	for i := range 5 * walkBatchSize {
+		files[fmt.Sprintf("d%d/f%d", i%10, i)] = ""
+	}
+	makeTree(t, dir, files)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan []string)
+	go Walk(ctx, dir, WalkOptions{}, out)
+
+	received := len(<-out)
+	cancel()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case batch, ok := <-out:
+			if !ok {
+				// The batch being sent may still get through, nothing after it
+				if received > 2*walkBatchSize {
+					t.Fatalf("got %d paths after cancelling", received)
+				}
+				return
+			}
+			received += len(batch)
+		case <-timeout:
+			t.Fatal("walk not stopped by cancelling")
+		}
+	}
+}
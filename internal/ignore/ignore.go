@@ -0,0 +1,199 @@
+package ignore
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Files are the ignore files read in each directory, in order of
+// precedence: .ignore overrides .gitignore, as with ripgrep
+var Files = []string{".gitignore", ".ignore"}
+
+// pattern is one rule of an ignore file
+type pattern struct {
+	segments []string // Glob of each path element, "**" matching any number
+	negate   bool     // Re-includes what earlier rules ignored
+	dirOnly  bool
+	anchored bool // Matched from the ignore file's directory, else by name
+}
+
+// Rules are the patterns read from the ignore files of one directory
+type Rules struct {
+	dir      string
+	patterns []pattern
+}
+
+// ParseRules reads the patterns of an ignore file in dir, in gitignore
+// syntax
+func ParseRules(dir string, data []byte) *Rules {
+	r := &Rules{dir: dir}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if p, ok := parsePattern(scanner.Text()); ok {
+			r.patterns = append(r.patterns, p)
+		}
+	}
+	return r
+}
+
+// parsePattern parses one line, skipping blanks and comments
+func parsePattern(line string) (pattern, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || line[0] == '#' {
+		return pattern{}, false
+	}
+
+	var p pattern
+	if line[0] == '!' {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		p.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return pattern{}, false
+	}
+	p.segments = strings.Split(line, "/")
+	return p, true
+}
+
+// Match reports whether the rules decide about the file at name, and
+// whether they ignore it. The last matching pattern wins.
+func (r *Rules) Match(name string, isDir bool) (matched, ignored bool) {
+	rel, err := filepath.Rel(r.dir, name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false, false
+	}
+	elems := strings.Split(filepath.ToSlash(rel), "/")
+
+	for i := len(r.patterns) - 1; i >= 0; i-- {
+		p := r.patterns[i]
+		if p.dirOnly && !isDir {
+			continue
+		}
+		ok := false
+		if p.anchored {
+			ok = matchSegments(p.segments, elems)
+		} else {
+			ok = matchSegments(p.segments, elems[len(elems)-1:])
+		}
+		if ok {
+			return true, !p.negate
+		}
+	}
+	return false, false
+}
+
+// matchSegments matches path elements against glob segments
+func matchSegments(segments, elems []string) bool {
+	if len(segments) == 0 {
+		return len(elems) == 0
+	}
+	if segments[0] == "**" {
+		if len(segments) == 1 {
+			return len(elems) > 0 // Only what is inside, as in foo/**
+		}
+		for i := 0; i <= len(elems); i++ {
+			if matchSegments(segments[1:], elems[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(elems) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(segments[0], elems[0]); !ok {
+		return false
+	}
+	return matchSegments(segments[1:], elems[1:])
+}
+
+// Matcher decides which entries the ignore files of their directory and
+// its parents ignore, up to the root of the repository. Nothing is ignored
+// outside of a repository. Rules are read once per directory until
+// forgotten. It is safe for concurrent use.
+type Matcher struct {
+	mu   sync.Mutex
+	dirs map[string]dirRules
+}
+
+// dirRules is what a Matcher read in one directory
+type dirRules struct {
+	rules    []*Rules // Nil if the directory has no ignore file
+	repoRoot bool
+}
+
+// NewMatcher creates a matcher that hasn't read any file yet
+func NewMatcher() *Matcher {
+	return &Matcher{dirs: make(map[string]dirRules)}
+}
+
+// Ignored reports whether the ignore files above name ignore it. Only the
+// entry itself is matched, so the contents of an ignored directory that
+// was entered anyway are listed.
+func (m *Matcher) Ignored(name string, isDir bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Find the directories up to the root of the repository
+	var dirs []dirRules
+	for dir := filepath.Dir(name); ; dir = filepath.Dir(dir) {
+		d := m.load(dir)
+		dirs = append(dirs, d)
+		if d.repoRoot {
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			return false
+		}
+	}
+
+	// The closest directories take precedence
+	for _, d := range dirs {
+		for i := len(d.rules) - 1; i >= 0; i-- {
+			if matched, ignored := d.rules[i].Match(name, isDir); matched {
+				return ignored
+			}
+		}
+	}
+	return false
+}
+
+// Forget drops the rules read in dir, to read them again after a change
+func (m *Matcher) Forget(dir string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.dirs, dir)
+}
+
+// load returns the rules of dir, reading them the first time; the caller
+// must hold m.mu
+func (m *Matcher) load(dir string) dirRules {
+	d, ok := m.dirs[dir]
+	if ok {
+		return d
+	}
+	for _, file := range Files {
+		if data, err := os.ReadFile(filepath.Join(dir, file)); err == nil {
+			d.rules = append(d.rules, ParseRules(dir, data))
+		}
+	}
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	d.repoRoot = err == nil
+	m.dirs[dir] = d
+	return d
+}
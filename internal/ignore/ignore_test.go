@@ -0,0 +1,124 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRulesMatch(t *testing.T) {
+	rules := ParseRules("/r", []byte(`# comment
+*.log
+!important.log
+build/
+/root.txt
+doc/*.md
+**/cache
+a/**/b
+out/**
+\#hash
+trailing   
+
+`))
+
+	tests := []struct {
+		name    string
+		isDir   bool
+		matched bool
+		ignored bool
+	}{
+		{"/r/x.log", false, true, true},
+		{"/r/sub/x.log", false, true, true},
+		{"/r/important.log", false, true, false},
+		{"/r/build", true, true, true},
+		{"/r/build", false, false, false},
+		{"/r/sub/build", true, true, true},
+		{"/r/root.txt", false, true, true},
+		{"/r/sub/root.txt", false, false, false},
+		{"/r/doc/a.md", false, true, true},
+		{"/r/doc/sub/a.md", false, false, false},
+		{"/r/sub/doc/a.md", false, false, false},
+		{"/r/cache", true, true, true},
+		{"/r/x/y/cache", true, true, true},
+		{"/r/a/b", false, true, true},
+		{"/r/a/x/y/b", false, true, true},
+		{"/r/b", false, false, false},
+		{"/r/out", true, false, false},
+		{"/r/out/x", false, true, true},
+		{"/r/out/x/y", true, true, true},
+		{"/r/#hash", false, true, true},
+		{"/r/# comment", false, false, false},
+		{"/r/trailing", false, true, true},
+		{"/other/x.log", false, false, false},
+		{"/r", true, false, false},
+	}
+	for _, tt := range tests {
+		matched, ignored := rules.Match(filepath.FromSlash(tt.name), tt.isDir)
+		if matched != tt.matched || ignored != tt.ignored {
+			t.Errorf("Match(%s, dir %v) = %v, %v, want %v, %v",
+				tt.name, tt.isDir, matched, ignored, tt.matched, tt.ignored)
+		}
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	top := t.TempDir()
+	repo := filepath.Join(top, "repo")
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(top, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".gitignore", "*.go\n") // Outside the repository
+	write("repo/.git/HEAD", "")
+	write("repo/.gitignore", "*.tmp\nvendor/\n")
+	write("repo/sub/.gitignore", "!keep.tmp\nsecret.txt\n")
+	write("repo/sub/.ignore", "!secret.txt\n")
+
+	m := NewMatcher()
+	tests := []struct {
+		name    string
+		isDir   bool
+		ignored bool
+	}{
+		{"a.tmp", false, true},
+		{"sub/a.tmp", false, true},
+		{"sub/keep.tmp", false, false},
+		{"vendor", true, true},
+		{"sub/vendor", true, true},
+		{"vendor", false, false},
+		{"sub/secret.txt", false, false},
+		{"main.go", false, false},
+		{"sub/deeper/a.tmp", false, true},
+	}
+	for _, tt := range tests {
+		if got := m.Ignored(filepath.Join(repo, tt.name), tt.isDir); got != tt.ignored {
+			t.Errorf("Ignored(%s, dir %v) = %v, want %v", tt.name, tt.isDir, got, tt.ignored)
+		}
+	}
+
+	// Outside of a repository no ignore file applies
+	write("plain/.gitignore", "*.tmp\n")
+	write("plain/sub/.ignore", "*.txt\n")
+	for _, name := range []string{"a.go", "plain/a.tmp", "plain/sub/a.txt", "plain/sub/a.go"} {
+		if m.Ignored(filepath.Join(top, name), false) {
+			t.Errorf("%s ignored outside of a repository", name)
+		}
+	}
+
+	// Rules are read once until forgotten
+	write("repo/sub/.gitignore", "x.txt\n")
+	x := filepath.Join(repo, "sub", "x.txt")
+	if m.Ignored(x, false) {
+		t.Error("changed rules used before Forget")
+	}
+	m.Forget(filepath.Join(repo, "sub"))
+	if !m.Ignored(x, false) {
+		t.Error("changed rules not used after Forget")
+	}
+}
@@ -42,6 +42,17 @@ func DefaultPreviewConfig() PreviewConfig {
 	}
 }
 
+// SyntaxThemes returns the names of the available highlighting themes
+func SyntaxThemes() []string {
+	return styles.Names()
+}
+
+// HasSyntaxTheme reports whether a highlighting theme exists
+func HasSyntaxTheme(name string) bool {
+	_, ok := styles.Registry[name]
+	return ok
+}
+
 // LoadPreview loads the preview content for a file
 func LoadPreview(file fs.FileInfo, maxLines int) PreviewContent {
 	config := DefaultPreviewConfig()
@@ -56,6 +67,13 @@ func LoadPreviewWithConfig(file fs.FileInfo, config PreviewConfig) PreviewConten
 		FileInfo: file,
 	}
 
+	// Symlinks that lead nowhere
+	if file.Broken {
+		preview.Content = "Can't preview, " + file.Description()
+		preview.IsText = false
+		return preview
+	}
+
 	// Handle directories
 	if file.IsDir {
 		preview.Content = loadDirectoryPreview(file.Path)
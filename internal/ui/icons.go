@@ -10,6 +10,9 @@ import (
 
 // GetFileIcon returns an icon for a file based on its type
 func GetFileIcon(file fs.FileInfo) string {
+	if file.IsLink {
+		return "🔗"
+	}
 	if file.IsDir {
 		return "📁"
 	}
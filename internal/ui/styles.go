@@ -10,6 +10,10 @@ type Styles struct {
 	SelectedFile lipgloss.Style
 	StatusBar    lipgloss.Style
 	EmptyDir     lipgloss.Style
+	Match        lipgloss.Style
+	Marked       lipgloss.Style
+	Symlink      lipgloss.Style
+	BrokenLink   lipgloss.Style
 }
 
 // DefaultStyles returns the default style configuration
@@ -40,5 +44,21 @@ func DefaultStyles() Styles {
 		EmptyDir: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("240")).
 			Align(lipgloss.Center, lipgloss.Center),
+
+		Match: lipgloss.NewStyle().
+			Bold(true).
+			Underline(true).
+			Foreground(lipgloss.Color("214")),
+
+		Marked: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("11")),
+
+		Symlink: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("14")),
+
+		BrokenLink: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("9")).
+			Italic(true),
 	}
 }
\ No newline at end of file
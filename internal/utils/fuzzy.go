@@ -0,0 +1,111 @@
+package utils
+
+import "unicode"
+
+// Scoring weights for FuzzyMatch
+const (
+	scoreMatch       = 16
+	bonusConsecutive = 8
+	bonusBoundary    = 10
+	penaltyGap       = 1
+	maxGapPenalty    = 12
+)
+
+// FuzzyMatch reports whether all runes of pattern appear in text in order,
+// returning a score (higher is better) and the rune positions that matched.
+// Matching ignores case unless pattern contains an upper-case letter.
+func FuzzyMatch(pattern, text string) (int, []int, bool) {
+	if pattern == "" {
+		return 0, nil, true
+	}
+
+	p := []rune(pattern)
+	t := []rune(text)
+	caseSensitive := hasUpper(p)
+	equal := func(a, b rune) bool {
+		if caseSensitive {
+			return a == b
+		}
+		return unicode.ToLower(a) == unicode.ToLower(b)
+	}
+
+	// Forward pass: find where the earliest complete match ends
+	end, pi := -1, 0
+	for ti := 0; ti < len(t); ti++ {
+		if equal(t[ti], p[pi]) {
+			pi++
+			if pi == len(p) {
+				end = ti
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	// Backward pass: find the latest start that still matches, which gives
+	// the tightest window ending at end
+	start := end
+	pi = len(p) - 1
+	for ti := end; ti >= 0; ti-- {
+		if equal(t[ti], p[pi]) {
+			pi--
+			if pi < 0 {
+				start = ti
+				break
+			}
+		}
+	}
+
+	positions := make([]int, 0, len(p))
+	score := 0
+	pi = 0
+	for ti := start; ti <= end && pi < len(p); ti++ {
+		if !equal(t[ti], p[pi]) {
+			continue
+		}
+
+		score += scoreMatch
+		if isBoundary(t, ti) {
+			score += bonusBoundary
+		}
+		if n := len(positions); n > 0 {
+			if gap := ti - positions[n-1] - 1; gap == 0 {
+				score += bonusConsecutive
+			} else {
+				score -= min(gap*penaltyGap, maxGapPenalty)
+			}
+		}
+
+		positions = append(positions, ti)
+		pi++
+	}
+
+	// Prefer shorter candidates when everything else is equal
+	score -= len(t) / 16
+	return score, positions, true
+}
+
+// isBoundary reports whether t[i] starts a word
+func isBoundary(t []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev, cur := t[i-1], t[i]
+	switch prev {
+	case '/', '\\', '_', '-', '.', ' ':
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
+
+// hasUpper reports whether any rune is upper-case
+func hasUpper(runes []rune) bool {
+	for _, r := range runes {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
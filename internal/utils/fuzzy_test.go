@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		ok            bool
+		positions     []int
+	}{
+		{"", "anything", true, nil},
+		{"abc", "abc", true, []int{0, 1, 2}},
+		{"abc", "a-b-c", true, []int{0, 2, 4}},
+		{"abc", "acb", false, nil},
+		{"ABC", "abc", false, nil}, // Upper case makes the match case sensitive
+		{"abc", "ABC", true, []int{0, 1, 2}},
+		{"mgo", "main.go", true, []int{0, 5, 6}},
+		{"ab", "a_a_b", true, []int{2, 4}}, // Tightest window ending at the first match
+		{"ñé", "añbé", true, []int{1, 3}},  // Rune positions
+		{"x", "", false, nil},
+	}
+	for _, tt := range tests {
+		_, positions, ok := FuzzyMatch(tt.pattern, tt.text)
+		if ok != tt.ok || !slices.Equal(positions, tt.positions) {
+			t.Errorf("FuzzyMatch(%q, %q) = %v, %v, want %v, %v",
+				tt.pattern, tt.text, positions, ok, tt.positions, tt.ok)
+		}
+	}
+}
+
+func TestFuzzyMatchScore(t *testing.T) {
+	// Each pair lists the better candidate first
+	tests := []struct {
+		pattern, better, worse string
+	}{
+		{"main", "main.go", "my_awesome_input.go"},  // Consecutive
+		{"fb", "foo_bar", "fooxbar"},                // Word boundaries
+		{"fb", "FooBar", "foobar"},                  // camelCase boundaries
+		{"read", "README.md", "docs/old/README.md"}, // Shorter
+	}
+	for _, tt := range tests {
+		better, _, ok1 := FuzzyMatch(tt.pattern, tt.better)
+		worse, _, ok2 := FuzzyMatch(tt.pattern, tt.worse)
+		if !ok1 || !ok2 || better <= worse {
+			t.Errorf("%q: %q scored %d, %q scored %d", tt.pattern, tt.better, better, tt.worse, worse)
+		}
+	}
+}
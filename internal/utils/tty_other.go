@@ -0,0 +1,11 @@
+//go:build !unix
+
+package utils
+
+import "os"
+
+// OpenTTY opens the console for writing, to draw the interface while stdout
+// is redirected
+func OpenTTY() (*os.File, error) {
+	return os.OpenFile("CONOUT$", os.O_RDWR, 0)
+}
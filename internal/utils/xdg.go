@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DataHome returns $XDG_DATA_HOME, defaulting to ~/.local/share
+func DataHome() string {
+	return xdgDir("XDG_DATA_HOME", ".local", "share")
+}
+
+// DataDir returns the directory sushi keeps its own data in
+func DataDir() string {
+	return filepath.Join(DataHome(), "sushi")
+}
+
+// ConfigHome returns $XDG_CONFIG_HOME, defaulting to ~/.config
+func ConfigHome() string {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// ConfigDir returns the directory sushi reads its configuration from
+func ConfigDir() string {
+	return filepath.Join(ConfigHome(), "sushi")
+}
+
+// xdgDir returns the directory in env if it is set to an absolute path,
+// otherwise the fallback below the user's home directory
+func xdgDir(env string, fallback ...string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.TempDir()
+	}
+	return filepath.Join(append([]string{home}, fallback...)...)
+}
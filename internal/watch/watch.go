@@ -0,0 +1,76 @@
+package watch
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnsupported is returned by New where changes can't be watched
+var ErrUnsupported = errors.New("watching for changes is not supported on this system")
+
+// debounceDelay is how long events are gathered before being reported,
+// so a burst of writes makes a single batch
+const debounceDelay = 150 * time.Millisecond
+
+// Changes is a batch of events on the watched paths
+type Changes struct {
+	Entries  map[string]map[string]bool // Names created, removed or modified, by watched directory
+	Paths    map[string]bool            // Watched paths modified, removed or moved themselves
+	Overflow bool                       // Events were lost, everything watched may have changed
+}
+
+// event is one change reported by the system: name changed in the watched
+// path, or the path itself if name is empty
+type event struct {
+	path     string
+	name     string
+	overflow bool
+}
+
+// add merges ev into the batch
+func (c *Changes) add(ev event) {
+	switch {
+	case ev.overflow:
+		c.Overflow = true
+	case ev.name == "":
+		c.Paths[ev.path] = true
+	default:
+		if c.Entries[ev.path] == nil {
+			c.Entries[ev.path] = make(map[string]bool)
+		}
+		c.Entries[ev.path][ev.name] = true
+	}
+}
+
+// debounce batches the events read from raw, sending each batch on out
+// debounceDelay after its first event. It stops when raw is closed or done
+// is, closing out.
+func debounce(raw <-chan event, out chan<- Changes, done <-chan struct{}) {
+	defer close(out)
+
+	for ev := range raw {
+		batch := Changes{Entries: make(map[string]map[string]bool), Paths: make(map[string]bool)}
+		batch.add(ev)
+
+		timer := time.NewTimer(debounceDelay)
+	gather:
+		for {
+			select {
+			case ev, ok := <-raw:
+				if !ok {
+					break gather
+				}
+				batch.add(ev)
+			case <-timer.C:
+				break gather
+			}
+		}
+		timer.Stop()
+
+		select {
+		case out <- batch:
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,188 @@
+//go:build linux
+
+package watch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// mask selects the events reported on watched paths
+const mask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO |
+	unix.IN_MODIFY | unix.IN_ATTRIB | unix.IN_CLOSE_WRITE |
+	unix.IN_DELETE_SELF | unix.IN_MOVE_SELF
+
+// Watcher reports changes to a set of paths, using inotify
+type Watcher struct {
+	C <-chan Changes // Receives the batches of changes
+
+	fd      int
+	file    *os.File // fd, read through the runtime poller so Close interrupts it
+	done    chan struct{}
+	mu      sync.Mutex
+	closed  bool
+	watches map[string]int // Watch descriptor of each path
+	paths   map[int]string // Path reported for each watch descriptor
+}
+
+// New starts a watcher that doesn't watch anything yet
+func New() (*Watcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make(chan event, 64)
+	out := make(chan Changes)
+	w := &Watcher{
+		C:       out,
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		done:    make(chan struct{}),
+		watches: make(map[string]int),
+		paths:   make(map[int]string),
+	}
+	go w.read(raw)
+	go debounce(raw, out, w.done)
+	return w, nil
+}
+
+// Close stops watching and closes C once pending changes are dropped
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+	return w.file.Close()
+}
+
+// Watch replaces the watched paths. Directories report changes to their
+// entries, files changes to their contents. Paths that can't be watched,
+// such as those already gone, are skipped.
+func (w *Watcher) Watch(paths ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+
+	want := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if path != "" {
+			want[path] = true
+		}
+	}
+
+	for path, wd := range w.watches {
+		if want[path] {
+			continue
+		}
+		delete(w.watches, path)
+		if other, ok := w.pathOf(wd); ok {
+			w.paths[wd] = other
+		} else {
+			delete(w.paths, wd)
+			unix.InotifyRmWatch(w.fd, uint32(wd))
+		}
+	}
+
+	for path := range want {
+		if _, ok := w.watches[path]; ok {
+			continue
+		}
+		wd, err := unix.InotifyAddWatch(w.fd, path, mask)
+		if err != nil {
+			continue
+		}
+		w.watches[path] = wd
+		if _, ok := w.paths[wd]; !ok {
+			w.paths[wd] = path // Paths to the same file share a descriptor
+		}
+	}
+}
+
+// pathOf returns a watched path still using wd; the caller must hold w.mu
+func (w *Watcher) pathOf(wd int) (string, bool) {
+	for path, other := range w.watches {
+		if other == wd {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// forget drops the paths watched through wd; the caller must hold w.mu
+func (w *Watcher) forget(wd int) {
+	delete(w.paths, wd)
+	for path, other := range w.watches {
+		if other == wd {
+			delete(w.watches, path)
+		}
+	}
+}
+
+// read decodes the inotify events until the watcher is closed
+func (w *Watcher) read(raw chan<- event) {
+	defer close(raw)
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil || n <= 0 {
+			return
+		}
+
+		for off := 0; off+unix.SizeofInotifyEvent <= n; {
+			wd := int(int32(binary.NativeEndian.Uint32(buf[off:])))
+			evMask := binary.NativeEndian.Uint32(buf[off+4:])
+			nameLen := int(binary.NativeEndian.Uint32(buf[off+12:]))
+			start := off + unix.SizeofInotifyEvent
+			name := string(bytes.TrimRight(buf[start:min(n, start+nameLen)], "\x00"))
+			off = start + nameLen
+
+			if evMask&unix.IN_Q_OVERFLOW != 0 {
+				if !w.send(raw, event{overflow: true}) {
+					return
+				}
+				continue
+			}
+
+			w.mu.Lock()
+			path, ok := w.paths[wd]
+			switch {
+			case evMask&unix.IN_IGNORED != 0:
+				// The watch is gone along with its path
+				w.forget(wd)
+				ok = false
+			case ok && evMask&unix.IN_MOVE_SELF != 0:
+				// The watch would follow the file, so the path gets a new one
+				// from the next call to Watch
+				w.forget(wd)
+				unix.InotifyRmWatch(w.fd, uint32(wd))
+			}
+			w.mu.Unlock()
+
+			if ok && !w.send(raw, event{path: path, name: name}) {
+				return
+			}
+		}
+	}
+}
+
+// send passes ev on to the debouncer, returning false once w is closed
+func (w *Watcher) send(raw chan<- event, ev event) bool {
+	select {
+	case raw <- ev:
+		return true
+	case <-w.done:
+		return false
+	}
+}
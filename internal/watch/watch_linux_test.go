@@ -0,0 +1,93 @@
+//go:build linux
+
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// next waits for the next batch of changes from w
+func next(t *testing.T, w *Watcher) Changes {
+	t.Helper()
+	select {
+	case c, ok := <-w.C:
+		if !ok {
+			t.Fatal("watcher closed")
+		}
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("no changes reported")
+	}
+	return Changes{}
+}
+
+func TestWatcher(t *testing.T) {
+	w, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w.Watch(dir, file)
+
+	os.WriteFile(file, []byte("changed"), 0o644)
+	os.WriteFile(filepath.Join(dir, "new"), nil, 0o644)
+	c := next(t, w)
+	if !c.Entries[dir]["file"] || !c.Entries[dir]["new"] || !c.Paths[file] {
+		t.Fatalf("got %+v, want file and new in %s and %s itself", c, dir, file)
+	}
+
+	// Paths left out of the set are no longer reported
+	w.Watch(dir)
+	os.WriteFile(file, []byte("again"), 0o644)
+	if c := next(t, w); c.Paths[file] {
+		t.Fatalf("got %+v, want %s unwatched", c, file)
+	}
+
+	// A moved path is watched afresh by the next Watch
+	sub := filepath.Join(dir, "sub")
+	os.Mkdir(sub, 0o755)
+	w.Watch(dir, sub)
+	next(t, w)
+	os.Rename(sub, filepath.Join(dir, "old"))
+	if c := next(t, w); !c.Paths[sub] {
+		t.Fatalf("got %+v, want %s itself", c, sub)
+	}
+	os.Mkdir(sub, 0o755)
+	next(t, w)
+	w.Watch(dir, sub)
+	os.WriteFile(filepath.Join(sub, "inside"), nil, 0o644)
+	if c := next(t, w); !c.Entries[sub]["inside"] {
+		t.Fatalf("got %+v, want inside in %s", c, sub)
+	}
+}
+
+func TestWatcherClose(t *testing.T) {
+	w, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Watch(t.TempDir())
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	w.Watch(t.TempDir())
+
+	select {
+	case _, ok := <-w.C:
+		if ok {
+			t.Fatal("got changes after Close")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("C not closed by Close")
+	}
+}
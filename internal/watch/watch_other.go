@@ -0,0 +1,22 @@
+//go:build !linux
+
+package watch
+
+// Watcher reports changes to a set of paths; it is only implemented on
+// Linux
+type Watcher struct {
+	C <-chan Changes // Receives the batches of changes
+}
+
+// New fails with ErrUnsupported
+func New() (*Watcher, error) {
+	return nil, ErrUnsupported
+}
+
+// Watch does nothing
+func (w *Watcher) Watch(paths ...string) {}
+
+// Close does nothing
+func (w *Watcher) Close() error {
+	return nil
+}
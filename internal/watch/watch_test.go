@@ -0,0 +1,130 @@
+package watch
+
+import (
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestChangesAdd(t *testing.T) {
+	tests := []struct {
+		name    string
+		events  []event
+		entries map[string][]string
+		paths   []string
+		over    bool
+	}{
+		{
+			name:    "entries by directory",
+			events:  []event{{path: "/a", name: "x"}, {path: "/a", name: "y"}, {path: "/b", name: "x"}},
+			entries: map[string][]string{"/a": {"x", "y"}, "/b": {"x"}},
+		},
+		{
+			name:    "repeated entry",
+			events:  []event{{path: "/a", name: "x"}, {path: "/a", name: "x"}},
+			entries: map[string][]string{"/a": {"x"}},
+		},
+		{
+			name:    "watched path itself",
+			events:  []event{{path: "/a/file"}, {path: "/a", name: "file"}},
+			entries: map[string][]string{"/a": {"file"}},
+			paths:   []string{"/a/file"},
+		},
+		{
+			name:    "overflow",
+			events:  []event{{path: "/a", name: "x"}, {overflow: true}},
+			entries: map[string][]string{"/a": {"x"}},
+			over:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Changes{Entries: make(map[string]map[string]bool), Paths: make(map[string]bool)}
+			for _, ev := range tt.events {
+				c.add(ev)
+			}
+
+			if len(c.Entries) != len(tt.entries) {
+				t.Errorf("got entries %v, want %v", c.Entries, tt.entries)
+			}
+			for dir, names := range tt.entries {
+				if !maps.Equal(c.Entries[dir], set(names...)) {
+					t.Errorf("got entries %v in %s, want %v", c.Entries[dir], dir, names)
+				}
+			}
+			if !maps.Equal(c.Paths, set(tt.paths...)) {
+				t.Errorf("got paths %v, want %v", c.Paths, tt.paths)
+			}
+			if c.Overflow != tt.over {
+				t.Errorf("got overflow %v, want %v", c.Overflow, tt.over)
+			}
+		})
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	raw := make(chan event)
+	out := make(chan Changes)
+	go debounce(raw, out, make(chan struct{}))
+
+	// A burst makes a single batch
+	start := time.Now()
+	raw <- event{path: "/a", name: "x"}
+	raw <- event{path: "/a", name: "y"}
+	raw <- event{path: "/b"}
+	batch := <-out
+	if elapsed := time.Since(start); elapsed < debounceDelay {
+		t.Errorf("batch sent after %v, before the %v delay", elapsed, debounceDelay)
+	}
+	if !maps.Equal(batch.Entries["/a"], set("x", "y")) || !batch.Paths["/b"] {
+		t.Errorf("got %+v, want x and y in /a and /b itself", batch)
+	}
+
+	// Later events start a new batch
+	raw <- event{path: "/a", name: "z"}
+	batch = <-out
+	if !maps.Equal(batch.Entries["/a"], set("z")) || len(batch.Paths) > 0 {
+		t.Errorf("got %+v, want only z in /a", batch)
+	}
+
+	// Closing raw flushes the batch being gathered, then closes out
+	raw <- event{path: "/a", name: "w"}
+	close(raw)
+	if batch := <-out; !batch.Entries["/a"]["w"] {
+		t.Errorf("got %+v, want w in /a", batch)
+	}
+	if _, ok := <-out; ok {
+		t.Error("out still open after raw was closed")
+	}
+}
+
+func TestDebounceDone(t *testing.T) {
+	raw := make(chan event)
+	out := make(chan Changes)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		debounce(raw, out, done)
+		close(stopped)
+	}()
+
+	// Nobody receives the batch, closing done must still stop it
+	raw <- event{path: "/a", name: "x"}
+	time.Sleep(2 * debounceDelay)
+	close(done)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("debounce still blocked sending after done was closed")
+	}
+}
+
+// set makes a set of names
+func set(names ...string) map[string]bool {
+	s := make(map[string]bool, len(names))
+	for _, name := range names {
+		s[name] = true
+	}
+	return s
+}
@@ -1,32 +1,166 @@
 package main
 
 import (
+	"embed"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/icichainz/sushi/internal/app"
+	"github.com/icichainz/sushi/internal/config"
+	"github.com/icichainz/sushi/internal/utils"
 )
 
+// shellScripts holds the cd-on-exit wrappers printed by -shell-init
+//
+//go:embed shell
+var shellScripts embed.FS
+
 func main() {
-	// Get starting directory (current dir or from args)
-	startPath, err := os.Getwd()
+	dumpConfig := flag.Bool("dump-default-config", false, "print the default configuration and exit")
+	configPath := flag.String("config", config.Path(), "read the configuration from `file`")
+	chooseDir := flag.String("choosedir", "", "write the last directory shown to `file` on quit")
+	selectionPath := flag.String("selection-path", "", "write the marked files to `file` on quit")
+	printSelection := flag.Bool("print-selection", false, "print the marked files on quit, drawing the interface on the terminal")
+	nulSeparated := flag.Bool("print0", false, "separate the files written on quit with NUL instead of newline")
+	pick := flag.Bool("pick", false, "choose files for another program: enter prints the file under the cursor and quits")
+	pickMultiple := flag.Bool("multiple", false, "with -pick, print every marked file")
+	dirsOnly := flag.Bool("dirs-only", false, "with -pick, only allow directories")
+	filesOnly := flag.Bool("files-only", false, "with -pick, only allow files")
+	shellInit := flag.String("shell-init", "", "print the cd-on-exit wrapper for `shell` (bash, zsh or fish) and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] [dir...]\n\nOptions:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *dumpConfig {
+		if err := config.Default().Write(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing config: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *shellInit != "" {
+		script, err := shellScripts.ReadFile("shell/sushi." + *shellInit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "No shell integration for %q, try bash, zsh or fish\n", *shellInit)
+			os.Exit(1)
+		}
+		os.Stdout.Write(script)
+		return
+	}
+
+	if *dirsOnly && *filesOnly {
+		fmt.Fprintln(os.Stderr, "-dirs-only and -files-only cannot be used together")
+		os.Exit(2)
+	}
+	if !*pick && (*pickMultiple || *dirsOnly || *filesOnly) {
+		fmt.Fprintln(os.Stderr, "-multiple, -dirs-only and -files-only need -pick")
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(*configPath)
 	if err != nil {
-		fmt.Printf("Error getting current directory: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Invalid configuration in %v\n", err)
 		os.Exit(1)
 	}
 
-	if len(os.Args) > 1 {
-		startPath = os.Args[1]
+	// Get starting directories (current dir or one tab per argument)
+	startPaths := flag.Args()
+	if len(startPaths) == 0 {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+		startPaths = []string{cwd}
+	}
+	for i, path := range startPaths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error resolving %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		startPaths[i] = abs
 	}
 
 	// Create the initial model
-	m := app.NewModel(startPath)
+	var m app.Model
+	if *pick {
+		m, err = app.NewPicker(startPaths, cfg, app.PickOptions{
+			Multiple:  *pickMultiple,
+			DirsOnly:  *dirsOnly,
+			FilesOnly: *filesOnly,
+		})
+	} else {
+		m, err = app.NewModel(startPaths, cfg)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration in %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+
+	// Keep stdout for the result when printing the selection or picking
+	options := []tea.ProgramOption{tea.WithAltScreen()}
+	if *printSelection || *pick {
+		tty, err := utils.OpenTTY()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening the terminal: %v\n", err)
+			os.Exit(1)
+		}
+		defer tty.Close()
+		options = append(options, tea.WithInputTTY(), tea.WithOutput(tty))
+	}
 
 	// Run the program
-	p := tea.NewProgram(m, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	p := tea.NewProgram(m, options...)
+	final, err := p.Run()
+	if err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
+
+	// Report where we ended up to the shell or program that started us
+	result := final.(app.Model)
+	separator := "\n"
+	if *nulSeparated {
+		separator = "\x00"
+	}
+	if *chooseDir != "" {
+		if err := os.WriteFile(*chooseDir, []byte(result.CurrentPath()), 0o600); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing the last directory: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *selectionPath != "" {
+		if err := os.WriteFile(*selectionPath, []byte(joinPaths(result.MarkedPaths(), separator)), 0o600); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing the selection: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *printSelection {
+		io.WriteString(os.Stdout, joinPaths(result.MarkedPaths(), separator))
+	}
+	if *pick {
+		if len(result.Picked()) == 0 {
+			os.Exit(1) // Cancelled
+		}
+		io.WriteString(os.Stdout, joinPaths(result.Picked(), separator))
+	}
+}
+
+// joinPaths ends each path with separator
+func joinPaths(paths []string, separator string) string {
+	var b strings.Builder
+	for _, path := range paths {
+		b.WriteString(path)
+		b.WriteString(separator)
+	}
+	return b.String()
 }
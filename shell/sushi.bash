@@ -0,0 +1,18 @@
+# sushi shell integration for bash. Add this to ~/.bashrc:
+#
+#   eval "$(sushi -shell-init bash)"
+#
+# Then browse with sushicd: quitting sushi leaves the shell in the last
+# directory it showed.
+sushicd() {
+    local tmp dir code
+    tmp="$(mktemp "${TMPDIR:-/tmp}/sushi-choosedir.XXXXXX")" || return
+    command sushi -choosedir "$tmp" "$@"
+    code=$?
+    dir="$(cat -- "$tmp")"
+    rm -f -- "$tmp"
+    if [ -n "$dir" ] && [ -d "$dir" ] && [ "$dir" != "$PWD" ]; then
+        cd -- "$dir" || return
+    fi
+    return "$code"
+}
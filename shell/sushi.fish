@@ -0,0 +1,17 @@
+# sushi shell integration for fish. Add this to ~/.config/fish/config.fish:
+#
+#   sushi -shell-init fish | source
+#
+# Then browse with sushicd: quitting sushi leaves the shell in the last
+# directory it showed.
+function sushicd --description 'Browse with sushi and cd to the last directory'
+    set -l tmp (mktemp (set -q TMPDIR; and echo $TMPDIR; or echo /tmp)/sushi-choosedir.XXXXXX); or return
+    command sushi -choosedir $tmp $argv
+    set -l code $status
+    set -l dir (cat -- $tmp)
+    rm -f -- $tmp
+    if test -n "$dir" -a -d "$dir" -a "$dir" != "$PWD"
+        cd -- $dir
+    end
+    return $code
+end